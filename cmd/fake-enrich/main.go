@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/fakeenrich"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+)
+
+// fake-enrich serves deterministic agify, genderize and nationalize
+// answers for local development. Point the service at it with
+//
+//	AGE_API=http://localhost:8090/age?name=
+//	GENDER_API=http://localhost:8090/gender?name=
+//	NATIONALIZE_API=http://localhost:8090/nationalize?name=
+func main() {
+	var opts fakeenrich.Options
+	var fault string
+
+	addr := flag.String("addr", ":8090", "address to listen on")
+	flag.Int64Var(&opts.Seed, "seed", 0, "seed for the generated answers")
+	flag.StringVar(&fault, "fault", string(fakeenrich.FaultNone), "fault mode: none, latency, 429, 500, empty_country, malformed_json")
+	flag.StringVar(&opts.FaultProvider, "fault-provider", "", "apply the fault only to age, gender or nationalize")
+	flag.DurationVar(&opts.Latency, "latency", 0, "response delay in latency mode")
+	flag.DurationVar(&opts.RetryAfter, "retry-after", time.Second, "Retry-After sent in 429 mode")
+	flag.Parse()
+
+	mode, err := fakeenrich.ParseFault(fault)
+	if err != nil {
+		usageError(err.Error())
+	}
+
+	if !fakeenrich.ValidProvider(opts.FaultProvider) {
+		usageError(fmt.Sprintf("unknown fault provider %q", opts.FaultProvider))
+	}
+
+	opts.Fault = mode
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	opts.Log = log
+
+	log.Info("starting fake enrichment server",
+		slog.String("addr", *addr),
+		slog.Int64("seed", opts.Seed),
+		slog.String("fault", fault),
+	)
+
+	if err := http.ListenAndServe(*addr, fakeenrich.New(opts)); err != nil {
+		log.Error("fake enrichment server stopped", sl.Err(err))
+		os.Exit(1)
+	}
+}
+
+func usageError(msg string) {
+	fmt.Fprintln(flag.CommandLine.Output(), msg)
+	flag.Usage()
+	os.Exit(2)
+}
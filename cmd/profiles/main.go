@@ -8,16 +8,14 @@ import (
 	"os/signal"
 	"syscall"
 
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
 	"github.com/stepan41k/Effective-Mobile/cmd/migrator"
 	"github.com/stepan41k/Effective-Mobile/internal/app"
 	"github.com/stepan41k/Effective-Mobile/internal/config"
+	enrichmentHandler "github.com/stepan41k/Effective-Mobile/internal/http-server/handlers/enrichment"
 	musicHandler "github.com/stepan41k/Effective-Mobile/internal/http-server/handlers/profile"
-	musicService "github.com/stepan41k/Effective-Mobile/internal/service/profile"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
 	"github.com/stepan41k/Effective-Mobile/internal/storage/postgres"
 	_ "github.com/stepan41k/Effective-Mobile/docs"
-	"github.com/swaggo/http-swagger/v2"
 )
 
 // @title Effective Mobile Test API
@@ -25,7 +23,7 @@ import (
 // @description API Server for Effective Mobile application
 
 // @host localhost:8082
-// @BasePath /profile
+// @BasePath /
 
 const (
 	envLocal = "local"
@@ -40,44 +38,38 @@ func main() {
 
 	log.Info("starting application")
 
-	router := chi.NewRouter()
-
-	router.Use(middleware.RequestID)
-	router.Use(middleware.Recoverer)
-	router.Use(middleware.URLFormat)
-
 	storagePath := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.Username, cfg.Storage.DBName, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.SSLMode)
 
 	pool, err := postgres.New(context.Background(), storagePath)
 	if err != nil {
 		panic(err)
 	}
-	service := musicService.New(pool, log)
+	enrich, err := app.NewEnrichment(log, cfg.Enrichment, pool)
+	if err != nil {
+		log.Error("failed to set up enrichment", sl.Err(err))
+		os.Exit(1)
+	}
+
+	service := app.NewProfileService(log, cfg.Enrichment, pool, enrich.Enricher)
 	handler := musicHandler.New(service, log)
+	statusHandler := enrichmentHandler.New(enrich.Client, enrich.Cache, log)
 
 	storagePathForMigrator := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", cfg.Storage.Username, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.DBName, cfg.Storage.SSLMode)
 
 	migrator.NewMigrator(storagePathForMigrator, os.Getenv("MY_MIGRATIONS_PATH"))
 
-	router.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:8082/swagger/doc.json"), //The url pointing to API definition
-	))
-
-	router.Route("/profile", func(r chi.Router) {
-		r.Post("/take", handler.TakeProfiles(context.Background()))
-		r.Delete("/remove", handler.RemoveProfile(context.Background()))
-		r.Patch("/update", handler.UpdateProfile(context.Background()))
-		r.Post("/new", handler.NewProfile(context.Background()))
-	})
+	router := app.NewRouter(handler, statusHandler)
 
 	log.Info("starting server")
 
-	application := app.New(log, cfg, router)
+	application := app.New(log, cfg, router, pool, service)
 
 	go func() {
 		application.HTTPServer.Run()
 	}()
 
+	application.Worker.Run()
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
 
@@ -87,6 +79,11 @@ func main() {
 
 	application.HTTPServer.Stop(context.Background())
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Worker.ShutdownTimeout)
+	defer cancel()
+
+	application.Worker.Stop(shutdownCtx)
+
 	postgres.Close(context.Background(), pool)
 
 	log.Info("application stopped")
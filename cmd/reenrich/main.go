@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/stepan41k/Effective-Mobile/internal/app"
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Effective-Mobile/internal/storage/postgres"
+)
+
+// reenrich refreshes age, gender and nationality of stored profiles. It
+// takes the same filters as POST /profile/take; without -page-size every
+// matching profile is re-enriched. Fields set manually are never touched.
+//
+//	reenrich -nationalize RU -age 30 -greater -workers 8
+func main() {
+	var req models.GetPerson
+
+	flag.StringVar(&req.Name, "name", "", "name filter")
+	flag.StringVar(&req.Surname, "surname", "", "surname filter")
+	flag.StringVar(&req.Patronymic, "patronymic", "", "patronymic filter")
+	flag.IntVar(&req.Age, "age", 0, "age filter, profiles younger than age unless -greater is set")
+	flag.BoolVar(&req.Greater, "greater", false, "match profiles older than -age")
+	flag.StringVar(&req.Gender, "gender", "", "gender filter")
+	flag.StringVar(&req.Nationalize, "nationalize", "", "nationality filter")
+	minGender := flag.Float64("min-gender-probability", 0, "minimum gender probability")
+	minCountry := flag.Float64("min-country-probability", 0, "minimum probability of the top country")
+	flag.IntVar(&req.Page, "page", 1, "page to re-enrich when -page-size is set")
+	flag.IntVar(&req.PageSize, "page-size", 0, "page size, 0 re-enriches every match")
+	where := flag.String("where", "", "query-string filters of GET /api/v1/profiles, e.g. 'age[gte]=20&gender[in]=male,female'")
+	workers := flag.Int("workers", 4, "profiles re-enriched at a time")
+	flag.Parse()
+
+	req.MinGenderProbability = float32(*minGender)
+	req.MinCountryProbability = float32(*minCountry)
+
+	if *where != "" {
+		q, err := url.ParseQuery(*where)
+		if err == nil {
+			req.Filter, err = filter.Parse(q)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -where: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	cfg := config.MustLoad()
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	storagePath := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.Username, cfg.Storage.DBName, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.SSLMode)
+
+	pool, err := postgres.New(ctx, storagePath)
+	if err != nil {
+		log.Error("failed to connect to storage", sl.Err(err))
+		os.Exit(1)
+	}
+	defer postgres.Close(context.Background(), pool)
+
+	enrich, err := app.NewEnrichment(log, cfg.Enrichment, pool)
+	if err != nil {
+		log.Error("failed to set up enrichment", sl.Err(err))
+		os.Exit(1)
+	}
+
+	service := app.NewProfileService(log, cfg.Enrichment, pool, enrich.Enricher)
+
+	summary, err := service.ReenrichProfiles(ctx, req, *workers)
+	if err != nil {
+		log.Error("re-enrichment stopped", sl.Err(err))
+	}
+
+	fmt.Printf("matched %d, refreshed %d, skipped %d, failed %d\n", summary.Matched, summary.Refreshed, summary.Skipped, summary.Failed)
+
+	if err != nil || summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
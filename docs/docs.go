@@ -15,7 +15,474 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/create": {
+        "/api/v1/profiles": {
+            "get": {
+                "description": "Outputs profiles matching the query-string filters, e.g. age[gte]=20\u0026age[lt]=40\u0026gender[in]=male,female",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "List",
+                "operationId": "list-profiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name, also name[ne|in|prefix|contains]",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "surname, also surname[ne|in|prefix|contains]",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "patronymic, also patronymic[ne|in|prefix|contains]",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "age, also age[ne|gt|gte|lt|lte|in]",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "gender, also gender[ne|in|prefix|contains]",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nationality, also nationalize[ne|in|prefix|contains]",
+                        "name": "nationalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "gender probability, also gender_probability[ne|gt|gte|lt|lte|in]",
+                        "name": "gender_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "probability of the top country, also country_probability[ne|gt|gte|lt|lte|in]",
+                        "name": "country_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma-separated sort keys, - for descending, e.g. surname,-age",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page, 1 by default",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size from 1 to 100, 10 by default",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor or prev_cursor of an earlier page, used instead of page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a profile and points to it in the Location header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Create",
+                "operationId": "create-profile-v1",
+                "parameters": [
+                    {
+                        "description": "name and surname is necessary",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewPerson"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/profiles/{guid}": {
+            "get": {
+                "description": "Outputs a single profile with its GUID and timestamps",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Get by GUID",
+                "operationId": "get-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "profile GUID",
+                        "name": "guid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the profile. Enriched fields that are sent become manual overrides; fields that are left out, patronymic included, are cleared",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Replace",
+                "operationId": "replace-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "profile GUID",
+                        "name": "guid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name and surname is necessary",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReplacedPerson"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the profile",
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Delete",
+                "operationId": "delete-profile-v1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "profile GUID",
+                        "name": "guid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates the fields that are sent. Enriched fields become manual overrides",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Patch",
+                "operationId": "patch-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "profile GUID",
+                        "name": "guid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "at least one field is necessary",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PatchedPerson"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/profiles/{guid}/enrich": {
+            "post": {
+                "description": "Looks the profile up again and refreshes age, gender and nationality. Fields set manually are skipped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Re-enrich",
+                "operationId": "reenrich-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "profile GUID",
+                        "name": "guid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/status": {
+            "get": {
+                "description": "Outputs circuit breaker and rate limit state of every enrichment provider and cache counters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Status",
+                "operationId": "enrichment-status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/new": {
             "post": {
                 "description": "Accepts name, surname and patronymic and creates profile",
                 "consumes": [
@@ -29,6 +496,7 @@ const docTemplate = `{
                 ],
                 "summary": "Create",
                 "operationId": "create-profile",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "name and surname is necessary",
@@ -50,31 +518,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "default": {
                         "description": "",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     }
                 }
             }
         },
-        "/delete": {
+        "/profile/remove": {
             "delete": {
                 "description": "Accepts profile GUID and remove this profile",
                 "consumes": [
@@ -88,6 +562,7 @@ const docTemplate = `{
                 ],
                 "summary": "Delete",
                 "operationId": "delete-profile",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "GUID is necessary",
@@ -106,28 +581,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.SuccessResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "default": {
                         "description": "",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     }
                 }
             }
         },
-        "/get": {
+        "/profile/take": {
             "post": {
                 "description": "Accepts filters and outputs profiles based on them",
                 "consumes": [
@@ -141,6 +628,7 @@ const docTemplate = `{
                 ],
                 "summary": "Get",
                 "operationId": "get-profiles",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "page and size of page is necessary",
@@ -156,31 +644,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.ListResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "default": {
                         "description": "",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     }
                 }
             }
         },
-        "/update": {
+        "/profile/update": {
             "patch": {
                 "description": "Accepts profile GUID and remove this profile",
                 "consumes": [
@@ -194,6 +688,7 @@ const docTemplate = `{
                 ],
                 "summary": "Update",
                 "operationId": "update-profile",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "GUID is necessary",
@@ -215,25 +710,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "409": {
                         "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     },
                     "default": {
                         "description": "",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "$ref": "#/definitions/response.Problem"
                         }
                     }
                 }
@@ -256,7 +763,6 @@ const docTemplate = `{
         "models.GetPerson": {
             "type": "object",
             "required": [
-                "page",
                 "page_size"
             ],
             "properties": {
@@ -264,6 +770,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 28
                 },
+                "cursor": {
+                    "description": "Cursor continues the listing from a next_cursor or prev_cursor of an\nearlier page. Page is ignored when it is set.",
+                    "type": "string"
+                },
                 "gender": {
                     "type": "string",
                     "example": "male"
@@ -272,6 +782,18 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": true
                 },
+                "min_country_probability": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.5
+                },
+                "min_gender_probability": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.9
+                },
                 "name": {
                     "type": "string",
                     "example": "John"
@@ -282,16 +804,23 @@ const docTemplate = `{
                 },
                 "page": {
                     "type": "integer",
+                    "minimum": 1,
                     "example": 3
                 },
                 "page_size": {
                     "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
                     "example": 10
                 },
                 "patronymic": {
                     "type": "string",
                     "example": "Ivanovich"
                 },
+                "sort": {
+                    "type": "string",
+                    "example": "surname,-age"
+                },
                 "surname": {
                     "type": "string",
                     "example": "Wick"
@@ -304,40 +833,109 @@ const docTemplate = `{
                 "name",
                 "surname"
             ],
+            "properties": {
+                "country_hint": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "guid": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 1,
+                    "example": "Igor"
+                },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 25,
+                    "minLength": 1,
+                    "example": "Vladimirovich"
+                },
+                "surname": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 1,
+                    "example": "Zaycev"
+                }
+            }
+        },
+        "models.PatchedPerson": {
+            "type": "object",
             "properties": {
                 "age": {
                     "type": "integer",
                     "maximum": 130,
-                    "minimum": 0
+                    "minimum": 0,
+                    "example": 33
                 },
                 "gender": {
                     "type": "string",
-                    "maxLength": 6
+                    "example": "male"
                 },
-                "guid": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 1,
+                    "example": "Valeriy"
+                },
+                "nationalize": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 25,
+                    "minLength": 1,
+                    "example": "Valentinovich"
+                },
+                "surname": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 1,
+                    "example": "Popov"
+                }
+            }
+        },
+        "models.ReplacedPerson": {
+            "type": "object",
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "age": {
+                    "type": "integer",
+                    "maximum": 130,
+                    "minimum": 0,
+                    "example": 33
+                },
+                "gender": {
+                    "type": "string",
+                    "example": "male"
                 },
                 "name": {
                     "type": "string",
                     "maxLength": 20,
                     "minLength": 1,
-                    "example": "Igor"
+                    "example": "Valeriy"
                 },
                 "nationalize": {
                     "type": "string",
-                    "maxLength": 10
+                    "example": "RU"
                 },
                 "patronymic": {
                     "type": "string",
                     "maxLength": 25,
                     "minLength": 1,
-                    "example": "Vladimirovich"
+                    "example": "Valentinovich"
                 },
                 "surname": {
                     "type": "string",
                     "maxLength": 30,
                     "minLength": 1,
-                    "example": "Zaycev"
+                    "example": "Popov"
                 }
             }
         },
@@ -355,7 +953,6 @@ const docTemplate = `{
                 },
                 "gender": {
                     "type": "string",
-                    "maxLength": 6,
                     "example": "male"
                 },
                 "guid": {
@@ -364,7 +961,6 @@ const docTemplate = `{
                 },
                 "nationalize": {
                     "type": "string",
-                    "maxLength": 3,
                     "example": "RU"
                 },
                 "new_name": {
@@ -387,14 +983,87 @@ const docTemplate = `{
                 }
             }
         },
-        "response.ErrorResponse": {
+        "response.FieldError": {
             "type": "object",
             "properties": {
-                "error": {
+                "field": {
+                    "type": "string"
+                },
+                "json_pointer": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ListMeta": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_estimated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.ListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "meta": {
+                    "$ref": "#/definitions/response.ListMeta"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.FieldError"
+                    }
+                },
+                "instance": {
                     "type": "string"
                 },
                 "status": {
                     "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
@@ -414,7 +1083,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "0.1",
 	Host:             "localhost:8082",
-	BasePath:         "/profile",
+	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Effective Mobile Test API",
 	Description:      "API Server for Effective Mobile application",
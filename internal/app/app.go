@@ -5,20 +5,24 @@ import (
 
 	"github.com/go-chi/chi"
 	httpapp "github.com/stepan41k/Effective-Mobile/internal/app/http"
+	workerapp "github.com/stepan41k/Effective-Mobile/internal/app/worker"
 	"github.com/stepan41k/Effective-Mobile/internal/config"
 )
 
 type App struct {
 	HTTPServer *httpapp.App
+	Worker *workerapp.App
 	log *slog.Logger
 }
 
-func New(log *slog.Logger, cfg *config.Config, router chi.Router) *App {
+func New(log *slog.Logger, cfg *config.Config, router chi.Router, jobs workerapp.Jobs, profiles workerapp.Profiles) *App {
 	
 	httpApp :=	httpapp.New(log, cfg, router)
+	workerApp := workerapp.New(log, cfg.Worker, jobs, profiles)
 	
 	return &App{
 		HTTPServer: httpApp,
+		Worker: workerApp,
 		log: log,
 	}
 }
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/cache"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/dataset"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/remote"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/translit"
+	musicService "github.com/stepan41k/Effective-Mobile/internal/service/profile"
+)
+
+const (
+	providerRemote  = "remote"
+	providerDataset = "dataset"
+)
+
+// Enrichment is the enrichment pipeline described by the config: the
+// providers in the configured order, the cache in front of them and
+// transliteration in front of the cache.
+type Enrichment struct {
+	Client   *remote.Client
+	Cache    *cache.Cache
+	Enricher enrichment.Enricher
+}
+
+func NewEnrichment(log *slog.Logger, cfg config.Enrichment, store cache.Store) (*Enrichment, error) {
+	const op = "app.NewEnrichment"
+
+	client := remote.New(log, cfg)
+
+	scheme, err := translit.ParseScheme(cfg.Transliteration)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	providers, err := setupProviders(log, cfg, client)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cached := cache.New(log, providers, store, cfg.CacheSize, cfg.CacheTTL)
+
+	return &Enrichment{
+		Client:   client,
+		Cache:    cached,
+		Enricher: enrichment.NewTransliterator(cached, scheme),
+	}, nil
+}
+
+// NewProfileService builds the profile service with the enrichment settings
+// from the config.
+func NewProfileService(log *slog.Logger, cfg config.Enrichment, profile musicService.Profile, enricher enrichment.Enricher) *musicService.ProfileService {
+	confidence := models.ConfidencePolicy{
+		MinAgeCount:           cfg.Confidence.MinAgeCount,
+		MinGenderProbability:  cfg.Confidence.MinGenderProbability,
+		MinCountryProbability: cfg.Confidence.MinCountryProbability,
+		LowConfidenceGender:   cfg.Confidence.LowConfidenceGender,
+	}
+
+	return musicService.New(profile, enricher, cfg.Policy, confidence, cfg.GenderRules, cfg.DefaultCountry, log)
+}
+
+func setupProviders(log *slog.Logger, cfg config.Enrichment, client *remote.Client) (enrichment.Enricher, error) {
+	var chain enrichment.Chain
+
+	for _, name := range cfg.Providers {
+		switch name {
+		case providerRemote:
+			chain = append(chain, client)
+		case providerDataset:
+			ds, err := dataset.Load(cfg.DatasetPath)
+			if err != nil {
+				return nil, err
+			}
+
+			log.Info("enrichment dataset loaded", slog.String("path", cfg.DatasetPath), slog.Int("names", ds.Len()))
+
+			chain = append(chain, ds)
+		default:
+			return nil, fmt.Errorf("unknown enrichment provider %q", name)
+		}
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil, fmt.Errorf("no enrichment providers configured")
+	case 1:
+		return chain[0], nil
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	enrichmentHandler "github.com/stepan41k/Effective-Mobile/internal/http-server/handlers/enrichment"
+	musicHandler "github.com/stepan41k/Effective-Mobile/internal/http-server/handlers/profile"
+	"github.com/stepan41k/Effective-Mobile/internal/http-server/middleware/deprecation"
+	"github.com/swaggo/http-swagger/v2"
+)
+
+// legacyDeprecatedSince is announced in the Deprecation header of the
+// /profile routes.
+var legacyDeprecatedSince = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// NewRouter mounts every route of the service.
+func NewRouter(handler *musicHandler.ProfileHandler, statusHandler *enrichmentHandler.EnrichmentHandler) chi.Router {
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Recoverer)
+	router.Use(middleware.URLFormat)
+
+	router.Get("/swagger/*", httpSwagger.Handler(
+		httpSwagger.URL("http://localhost:8082/swagger/doc.json"), //The url pointing to API definition
+	))
+
+	// The RPC-style routes predate /api/v1/profiles and are kept as
+	// deprecated aliases.
+	router.Route("/profile", func(r chi.Router) {
+		r.Use(deprecation.New(legacyDeprecatedSince, musicHandler.ResourcePath))
+
+		r.Post("/take", handler.TakeProfiles(context.Background()))
+		r.Delete("/remove", handler.RemoveProfile(context.Background()))
+		r.Patch("/update", handler.UpdateProfile(context.Background()))
+		r.Post("/new", handler.NewProfile(context.Background()))
+	})
+
+	router.Route(musicHandler.ResourcePath, func(r chi.Router) {
+		r.Get("/", handler.ListProfiles())
+		r.Post("/", handler.CreateProfile())
+		r.Get("/{guid}", handler.TakeProfile())
+		r.Put("/{guid}", handler.ReplaceProfile())
+		r.Patch("/{guid}", handler.PatchProfile())
+		r.Delete("/{guid}", handler.DeleteProfile())
+		r.Post("/{guid}/enrich", handler.ReenrichProfile())
+	})
+
+	router.Get("/enrichment/status", statusHandler.Status())
+
+	return router
+}
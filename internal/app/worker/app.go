@@ -0,0 +1,205 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/backoff"
+	"github.com/stepan41k/Effective-Mobile/internal/service"
+)
+
+type Jobs interface {
+	ClaimEnrichmentJobs(ctx context.Context, limit int, lease time.Duration) (jobs []models.EnrichmentJob, err error)
+	DeleteEnrichmentJob(ctx context.Context, id int64) (err error)
+	RescheduleEnrichmentJob(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr string, failed bool) (err error)
+}
+
+type Profiles interface {
+	EnrichProfile(ctx context.Context, person models.EnrichedPerson) (pending []string, err error)
+}
+
+type App struct {
+	log      *slog.Logger
+	cfg      config.Worker
+	jobs     Jobs
+	profiles Profiles
+	backoff  backoff.Backoff
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	// ctx is the parent of every job context. Stop cancels it when the
+	// in-flight jobs outlive the shutdown timeout.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func New(log *slog.Logger, cfg config.Worker, jobs Jobs, profiles Profiles) *App {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &App{
+		log:      log,
+		cfg:      cfg,
+		jobs:     jobs,
+		profiles: profiles,
+		backoff:  backoff.Backoff{Base: cfg.RetryBaseDelay, Max: cfg.RetryMaxDelay},
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (a *App) Run() {
+	const op = "workerapp.Run"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int("workers", a.cfg.Workers),
+	)
+
+	for i := 0; i < a.cfg.Workers; i++ {
+		a.wg.Add(1)
+		go a.work()
+	}
+
+	log.Info("enrichment workers started")
+}
+
+// Stop stops claiming new jobs and waits for the in-flight ones until ctx
+// is done, then cancels them and waits for the workers to return, so the
+// storage is no longer in use. Unfinished jobs are picked up again once
+// their lease expires.
+func (a *App) Stop(ctx context.Context) {
+	const op = "workerapp.Stop"
+
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("stopping enrichment workers")
+
+	close(a.stop)
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("enrichment workers did not stop in time, cancelling in-flight jobs")
+
+		a.cancel()
+		<-done
+	}
+
+	a.cancel()
+
+	log.Info("enrichment workers stopped")
+}
+
+func (a *App) work() {
+	defer a.wg.Done()
+
+	const op = "workerapp.work"
+
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	for {
+		select {
+		case <-a.stop:
+			return
+		default:
+		}
+
+		jobs, err := a.claim()
+		if err != nil && a.ctx.Err() == nil {
+			log.Error("failed to claim enrichment jobs", sl.Err(err))
+		}
+
+		for _, job := range jobs {
+			a.process(job)
+		}
+
+		if len(jobs) > 0 {
+			continue
+		}
+
+		select {
+		case <-a.stop:
+			return
+		case <-time.After(a.cfg.PollInterval):
+		}
+	}
+}
+
+func (a *App) claim() ([]models.EnrichmentJob, error) {
+	ctx, cancel := context.WithTimeout(a.ctx, a.cfg.JobTimeout)
+	defer cancel()
+
+	return a.jobs.ClaimEnrichmentJobs(ctx, a.cfg.BatchSize, a.cfg.Lease)
+}
+
+func (a *App) process(job models.EnrichmentJob) {
+	const op = "workerapp.process"
+
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Int64("job_id", job.ID),
+		slog.String("guid", job.Person.GUID),
+	)
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.cfg.JobTimeout)
+	defer cancel()
+
+	_, err := a.profiles.EnrichProfile(ctx, job.Person)
+	if err != nil && a.ctx.Err() != nil {
+		log.Warn("enrichment job interrupted by shutdown", sl.Err(err))
+
+		return
+	}
+
+	if err == nil || errors.Is(err, service.ErrProfileNotFound) {
+		if err := a.jobs.DeleteEnrichmentJob(ctx, job.ID); err != nil {
+			log.Error("failed to delete enrichment job", sl.Err(err))
+		}
+
+		return
+	}
+
+	var (
+		runAt  time.Time
+		failed bool
+	)
+
+	var rateErr *enrichment.RateLimitError
+	if errors.As(err, &rateErr) {
+		runAt = rateErr.Until
+
+		log.Warn("provider rate limited, postponing enrichment job", slog.Time("run_at", runAt))
+	} else {
+		job.Attempts++
+		runAt = time.Now().Add(a.backoff.Delay(job.Attempts))
+
+		if job.Attempts >= a.cfg.MaxAttempts {
+			failed = true
+
+			log.Error("enrichment job failed permanently", slog.Int("attempts", job.Attempts), sl.Err(err))
+		} else {
+			log.Warn("enrichment job failed, retrying", slog.Int("attempts", job.Attempts), slog.Time("run_at", runAt), sl.Err(err))
+		}
+	}
+
+	if err := a.jobs.RescheduleEnrichmentJob(ctx, job, runAt, err.Error(), failed); err != nil {
+		log.Error("failed to reschedule enrichment job", sl.Err(err))
+	}
+}
@@ -10,13 +10,15 @@ import (
 )
 
 type Config struct {
-	Env     string     `yaml:"env" env-default:"local"`
-	Server  HTTPServer `yaml:"http_server"`
-	Storage DataBase   `yaml:"db"`
+	Env        string     `yaml:"env" env-default:"local"`
+	Server     HTTPServer `yaml:"http_server"`
+	Storage    DataBase   `yaml:"db"`
+	Enrichment Enrichment `yaml:"enrichment"`
+	Worker     Worker     `yaml:"worker"`
 }
 
 type HTTPServer struct {
-	Port string `yaml:"server_port"`
+	Port         string        `yaml:"server_port"`
 	Timeout      time.Duration `yaml:"timeout" env-default:"4s"`
 	Idle_timeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
 }
@@ -29,6 +31,50 @@ type DataBase struct {
 	SSLMode  string `yaml:"sslmode"`
 }
 
+type Enrichment struct {
+	Policy             string        `yaml:"policy" env-default:"strict"`
+	Providers          []string      `yaml:"providers" env-default:"remote"`
+	DatasetPath        string        `yaml:"dataset_path"`
+	Transliteration    string        `yaml:"transliteration" env-default:"icao"`
+	GenderRules        string        `yaml:"gender_rules" env-default:"fallback"`
+	DefaultCountry     string        `yaml:"default_country"`
+	AgeURL             string        `yaml:"age_url" env:"AGE_API"`
+	GenderURL          string        `yaml:"gender_url" env:"GENDER_API"`
+	NationalizeURL     string        `yaml:"nationalize_url" env:"NATIONALIZE_API"`
+	AgeTimeout         time.Duration `yaml:"age_timeout" env-default:"3s"`
+	GenderTimeout      time.Duration `yaml:"gender_timeout" env-default:"3s"`
+	NationalizeTimeout time.Duration `yaml:"nationalize_timeout" env-default:"3s"`
+	MaxRetries         int           `yaml:"max_retries" env-default:"2"`
+	RetryBaseDelay     time.Duration `yaml:"retry_base_delay" env-default:"100ms"`
+	RetryMaxDelay      time.Duration `yaml:"retry_max_delay" env-default:"1s"`
+	BreakerThreshold   int           `yaml:"breaker_threshold" env-default:"5"`
+	BreakerCooldown    time.Duration `yaml:"breaker_cooldown" env-default:"30s"`
+	BatchSize          int           `yaml:"batch_size" env-default:"10"`
+	BatchWait          time.Duration `yaml:"batch_wait" env-default:"20ms"`
+	CacheSize          int           `yaml:"cache_size" env-default:"10000"`
+	CacheTTL           time.Duration `yaml:"cache_ttl" env-default:"168h"`
+	Confidence         Confidence    `yaml:"confidence"`
+}
+
+type Confidence struct {
+	MinAgeCount           int     `yaml:"min_age_count" env-default:"0"`
+	MinGenderProbability  float32 `yaml:"min_gender_probability" env-default:"0"`
+	MinCountryProbability float32 `yaml:"min_country_probability" env-default:"0"`
+	LowConfidenceGender   string  `yaml:"low_confidence_gender" env-default:"unknown"`
+}
+
+type Worker struct {
+	Workers         int           `yaml:"workers" env-default:"4"`
+	BatchSize       int           `yaml:"batch_size" env-default:"1"`
+	PollInterval    time.Duration `yaml:"poll_interval" env-default:"1s"`
+	Lease           time.Duration `yaml:"lease" env-default:"1m"`
+	JobTimeout      time.Duration `yaml:"job_timeout" env-default:"30s"`
+	MaxAttempts     int           `yaml:"max_attempts" env-default:"10"`
+	RetryBaseDelay  time.Duration `yaml:"retry_base_delay" env-default:"5s"`
+	RetryMaxDelay   time.Duration `yaml:"retry_max_delay" env-default:"10m"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"30s"`
+}
+
 func MustLoad() *Config {
 	if err := godotenv.Load(); err != nil {
 		log.Fatalf("error loading env variables: %s", err.Error())
@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Cursor marks a position in a sorted listing: the sort key values and the
+// guid of a row. With Before set it points at the rows preceding that row.
+// Clients get it as an opaque token.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+	GUID   string `json:"g"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// cursorFields encodes a Cursor without its text marshalling methods.
+type cursorFields Cursor
+
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+	}
+
+	var c cursorFields
+	if err := json.Unmarshal(raw, &c); err != nil || c.GUID == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+	}
+
+	return (*Cursor)(&c), nil
+}
+
+func (c *Cursor) String() string {
+	raw, _ := json.Marshal((*cursorFields)(c))
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func (c *Cursor) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Cursor) UnmarshalText(text []byte) error {
+	decoded, err := DecodeCursor(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = *decoded
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package filter is the typed filter tree behind the query-string filter
+// language of the profiles list:
+//
+//	age[gte]=20&age[lt]=40&gender[in]=male,female&nationalize[ne]=RU
+//	name[prefix]=Iv&surname[contains]=ov
+//
+// A bare parameter such as name=Ivan is the same as name[eq]=Ivan. String
+// comparisons are case-sensitive for every operator, and the value of
+// prefix and contains is matched literally.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid filter")
+
+type Field string
+
+const (
+	FieldName               Field = "name"
+	FieldSurname            Field = "surname"
+	FieldPatronymic         Field = "patronymic"
+	FieldAge                Field = "age"
+	FieldGender             Field = "gender"
+	FieldNationalize        Field = "nationalize"
+	FieldGenderProbability  Field = "gender_probability"
+	FieldCountryProbability Field = "country_probability"
+)
+
+type Op string
+
+const (
+	OpEq       Op = "eq"
+	OpNe       Op = "ne"
+	OpGt       Op = "gt"
+	OpGte      Op = "gte"
+	OpLt       Op = "lt"
+	OpLte      Op = "lte"
+	OpIn       Op = "in"
+	OpPrefix   Op = "prefix"
+	OpContains Op = "contains"
+)
+
+// Kind is the type of the values a field is compared with.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+)
+
+var kinds = map[Field]Kind{
+	FieldName:               KindString,
+	FieldSurname:            KindString,
+	FieldPatronymic:         KindString,
+	FieldAge:                KindInt,
+	FieldGender:             KindString,
+	FieldNationalize:        KindString,
+	FieldGenderProbability:  KindFloat,
+	FieldCountryProbability: KindFloat,
+}
+
+var ops = map[Kind][]Op{
+	KindString: {OpEq, OpNe, OpIn, OpPrefix, OpContains},
+	KindInt:    {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn},
+	KindFloat:  {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn},
+}
+
+// KindOf reports the kind of field and whether field is known.
+func KindOf(field Field) (Kind, bool) {
+	k, ok := kinds[field]
+
+	return k, ok
+}
+
+// Expr is a node of the filter tree: an And or a Condition.
+type Expr interface {
+	expr()
+}
+
+// And matches when every expression matches. An empty And matches
+// everything.
+type And []Expr
+
+// Condition compares a field with one value, or with a list of values for
+// OpIn. Values hold string, int or float64 according to the field Kind.
+type Condition struct {
+	Field  Field
+	Op     Op
+	Values []any
+}
+
+func (And) expr()       {}
+func (Condition) expr() {}
+
+// Parse reads the filter parameters from a query string. Parameters that
+// are not filters, such as page or sort, are ignored.
+func Parse(q url.Values) (And, error) {
+	var and And
+
+	// Keys are sorted so the same query always compiles to the same SQL.
+	for _, key := range slices.Sorted(maps.Keys(q)) {
+		field, op, ok := splitKey(key)
+		if !ok {
+			continue
+		}
+
+		for _, raw := range q[key] {
+			cond, err := NewCondition(field, op, raw)
+			if err != nil {
+				return nil, err
+			}
+
+			and = append(and, cond)
+		}
+	}
+
+	return and, nil
+}
+
+// NewCondition builds a condition from its query-string form, checking the
+// operator against the field and converting the value to the field Kind.
+func NewCondition(field Field, op Op, raw string) (Condition, error) {
+	kind, ok := kinds[field]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: unknown field %q", ErrInvalid, field)
+	}
+
+	if !slices.Contains(ops[kind], op) {
+		return Condition{}, fmt.Errorf("%w: operator %q is not supported for %s", ErrInvalid, op, field)
+	}
+
+	raws := []string{raw}
+	if op == OpIn {
+		raws = strings.Split(raw, ",")
+	}
+
+	cond := Condition{Field: field, Op: op}
+	for _, r := range raws {
+		v, err := convert(kind, strings.TrimSpace(r))
+		if err != nil {
+			return Condition{}, fmt.Errorf("%w: %s[%s]: %w", ErrInvalid, field, op, err)
+		}
+
+		cond.Values = append(cond.Values, v)
+	}
+
+	return cond, nil
+}
+
+// splitKey splits "age[gte]" into its field and operator. Keys that do not
+// name a known field are not filters, unless they carry an operator.
+func splitKey(key string) (Field, Op, bool) {
+	name, rest, found := strings.Cut(key, "[")
+	if !found {
+		if _, ok := kinds[Field(key)]; !ok {
+			return "", "", false
+		}
+
+		return Field(key), OpEq, true
+	}
+
+	op, ok := strings.CutSuffix(rest, "]")
+	if !ok {
+		return Field(key), "", true
+	}
+
+	return Field(name), Op(op), true
+}
+
+func convert(kind Kind, raw string) (any, error) {
+	switch kind {
+	case KindInt:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+
+		return v, nil
+	case KindFloat:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+
+		return v, nil
+	}
+
+	if raw == "" {
+		return nil, errors.New("value is empty")
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,93 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	FieldCreatedAt Field = "created_at"
+	FieldUpdatedAt Field = "updated_at"
+)
+
+// sortable is the whitelist of fields a listing can be ordered by.
+var sortable = map[Field]bool{
+	FieldName:              true,
+	FieldSurname:           true,
+	FieldPatronymic:        true,
+	FieldAge:               true,
+	FieldGender:            true,
+	FieldNationalize:       true,
+	FieldGenderProbability: true,
+	FieldCreatedAt:         true,
+	FieldUpdatedAt:         true,
+}
+
+type SortKey struct {
+	Field Field
+	Desc  bool
+}
+
+// Sort is an ordering such as "surname,-age": keys are separated by commas
+// and a leading minus sorts in descending order. It decodes from a JSON
+// string.
+type Sort []SortKey
+
+func ParseSort(s string) (Sort, error) {
+	var sort Sort
+
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	seen := make(map[Field]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		key := SortKey{Field: Field(part)}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			key = SortKey{Field: Field(name), Desc: true}
+		}
+
+		if !sortable[key.Field] {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, key.Field)
+		}
+
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%w: %s is sorted by twice", ErrInvalid, key.Field)
+		}
+		seen[key.Field] = true
+
+		sort = append(sort, key)
+	}
+
+	return sort, nil
+}
+
+func (s Sort) String() string {
+	parts := make([]string, 0, len(s))
+	for _, key := range s {
+		if key.Desc {
+			parts = append(parts, "-"+string(key.Field))
+		} else {
+			parts = append(parts, string(key.Field))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (s Sort) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Sort) UnmarshalText(text []byte) error {
+	sort, err := ParseSort(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = sort
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package models
+
+const (
+	LowConfidenceUnknown = "unknown"
+	LowConfidenceOther   = "other"
+)
+
+// ConfidencePolicy is the set of thresholds an inferred value has to meet
+// to be accepted.
+type ConfidencePolicy struct {
+	MinAgeCount           int     `json:"min_age_count"`
+	MinGenderProbability  float32 `json:"min_gender_probability"`
+	MinCountryProbability float32 `json:"min_country_probability"`
+	LowConfidenceGender   string  `json:"low_confidence_gender"`
+}
@@ -6,3 +6,6 @@ type Gender struct {
 	Gender      string  `json:"gender"`
 	Probability float32 `json:"probability"`
 }
+
+// Genders are the values of the gen enum in Postgres.
+var Genders = []string{"male", "female", "other"}
@@ -0,0 +1,23 @@
+package models
+
+type EnrichmentJob struct {
+	ID       int64
+	Attempts int
+	Person   EnrichedPerson
+}
+
+// ReenrichResult reports what re-enriching a profile changed. Fields set
+// manually are skipped; fields whose provider failed keep their values.
+type ReenrichResult struct {
+	GUID      string   `json:"guid"`
+	Refreshed []string `json:"refreshed_fields"`
+	Skipped   []string `json:"skipped_fields,omitempty"`
+	Failed    []string `json:"failed_fields,omitempty"`
+}
+
+type ReenrichSummary struct {
+	Matched   int
+	Refreshed int
+	Skipped   int
+	Failed    int
+}
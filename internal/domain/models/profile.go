@@ -1,29 +1,80 @@
 package models
 
+import (
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+)
+
+const (
+	FieldAge         = "age"
+	FieldGender      = "gender"
+	FieldNationalize = "nationalize"
+)
+
+const (
+	EnrichmentComplete = "complete"
+	EnrichmentPartial  = "partial"
+	EnrichmentPending  = "pending"
+	// EnrichmentFailed profiles still have pending fields, but their job
+	// ran out of attempts.
+	EnrichmentFailed = "failed"
+)
+
 type Person struct {
-	Name        string `json:"name"`
-	Surname     string `json:"surname"`
-	Patronymic  string `json:"patronymic,omitempty"`
-	Age         int    `json:"age,omitempty"`
-	Gender      string `json:"gender,omitempty"`
-	Nationalize string `json:"nationalize,omitempty"`
+	GUID              string    `json:"guid"`
+	Name              string    `json:"name"`
+	Surname           string    `json:"surname"`
+	Patronymic        string    `json:"patronymic,omitempty"`
+	QueryName         string    `json:"query_name,omitempty"`
+	CountryHint       string    `json:"country_hint,omitempty"`
+	Age               int       `json:"age,omitempty"`
+	AgeCount          int       `json:"age_count,omitempty"`
+	Gender            string    `json:"gender,omitempty"`
+	GenderProbability float32   `json:"gender_probability,omitempty"`
+	GenderCount       int       `json:"gender_count,omitempty"`
+	GenderSource      string    `json:"gender_source,omitempty"`
+	Nationalize       string    `json:"nationalize,omitempty"`
+	Countries         []Country `json:"countries,omitempty"`
+	EnrichmentStatus  string    `json:"enrichment_status"`
+	PendingFields     []string  `json:"pending_fields,omitempty"`
+	LowConfidence     []string  `json:"low_confidence_fields,omitempty"`
+	ConfidencePolicy  *ConfidencePolicy `json:"confidence_policy,omitempty"`
+	Provenance        Provenance `json:"provenance,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 type NewPerson struct {
 	GUID        string `json:"guid,omitempty" validate:"omitempty"`
-	Name        string `json:"name" validate:"required,min=1,max=20" example:"Igor"`
-	Surname     string `json:"surname" validate:"required,min=1,max=30" example:"Zaycev"`
-	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,min=1,max=25" example:"Vladimirovich"`
+	Name        string `json:"name" validate:"required,min=1,max=20,personname" example:"Igor"`
+	Surname     string `json:"surname" validate:"required,min=1,max=30,personname" example:"Zaycev"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,min=1,max=25,personname" example:"Vladimirovich"`
+	CountryHint string `json:"country_hint,omitempty" validate:"omitempty,country" example:"RU"`
 }
 
 type EnrichedPerson struct {
-	GUID        string `json:"guid"`
-	Name        string `json:"name"`
-	Surname     string `json:"surname"`
-	Patronymic  string `json:"patronymic"`
-	Age         int    `json:"age"`
-	Gender      string `json:"gender"`
-	Nationalize string `json:"nationalize"`
+	GUID              string    `json:"guid"`
+	Name              string    `json:"name"`
+	Surname           string    `json:"surname"`
+	Patronymic        string    `json:"patronymic"`
+	QueryName         string    `json:"query_name"`
+	CountryHint       string    `json:"country_hint"`
+	Age               int       `json:"age"`
+	AgeCount          int       `json:"age_count"`
+	Gender            string    `json:"gender"`
+	GenderProbability float32   `json:"gender_probability"`
+	GenderCount       int       `json:"gender_count"`
+	GenderSource      string    `json:"gender_source"`
+	Nationalize       string    `json:"nationalize"`
+	Countries         []Country `json:"countries"`
+	EnrichmentStatus  string    `json:"enrichment_status"`
+	PendingFields     []string  `json:"pending_fields"`
+	InferredAge       int       `json:"inferred_age"`
+	InferredGender    string    `json:"inferred_gender"`
+	LowConfidence     []string  `json:"low_confidence_fields"`
+	ConfidencePolicy  *ConfidencePolicy `json:"confidence_policy"`
+	Provenance        Provenance `json:"provenance"`
 }
 
 type GetPerson struct {
@@ -34,20 +85,74 @@ type GetPerson struct {
 	Greater     bool   `json:"greater,omitempty" example:"true"`
 	Gender      string `json:"gender,omitempty" example:"male"`
 	Nationalize string `json:"nationalize,omitempty" example:"US"`
-	PageSize    int    `json:"page_size" validate:"required" example:"10"`
-	Page        int    `json:"page" validate:"required" example:"3"`
+	MinGenderProbability  float32 `json:"min_gender_probability,omitempty" validate:"omitempty,gte=0,lte=1" example:"0.9"`
+	MinCountryProbability float32 `json:"min_country_probability,omitempty" validate:"omitempty,gte=0,lte=1" example:"0.5"`
+	PageSize    int    `json:"page_size" validate:"required,gte=1,lte=100" example:"10"`
+	Page        int    `json:"page" validate:"required_without=Cursor,omitempty,gte=1" example:"3"`
+	Sort        filter.Sort `json:"sort,omitempty" swaggertype:"string" example:"surname,-age"`
+	// Cursor continues the listing from a next_cursor or prev_cursor of an
+	// earlier page. Page is ignored when it is set.
+	Cursor *filter.Cursor `json:"cursor,omitempty" swaggertype:"string"`
+	// Filter holds the query-string conditions of the list endpoint. They
+	// are combined with the fields above.
+	Filter filter.And `json:"-" swaggerignore:"true"`
+}
+
+// ProfilePage is one page of a listing with the cursors of the pages next
+// to it. A cursor is empty when there is no page in that direction.
+type ProfilePage struct {
+	Profiles   []Person
+	NextCursor string
+	PrevCursor string
+	// Total counts every match of the filters, not only this page. It is
+	// the planner's estimate when TotalEstimated is set.
+	Total          int
+	TotalEstimated bool
+	// Page is the offset page number, zero for cursor pages.
+	Page    int
+	HasMore bool
 }
 
 type UpdatedPerson struct {
 	GUID        string `json:"guid" validate:"required" example:"3EWQbnsu-2!IHY389-ewqh312"`
-	Name        string `json:"new_name,omitempty" validate:"omitempty,min=1,max=20" example:"Valeriy"`
-	Surname     string `json:"new_surname,omitempty" validate:"omitempty,min=1,max=30" example:"Popov"`
-	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,min=1,max=25" example:"Valentinovich"`
+	Name        string `json:"new_name,omitempty" validate:"omitempty,min=1,max=20,personname" example:"Valeriy"`
+	Surname     string `json:"new_surname,omitempty" validate:"omitempty,min=1,max=30,personname" example:"Popov"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,min=1,max=25,personname" example:"Valentinovich"`
 	Age         int    `json:"age,omitempty" validate:"omitempty,gte=0,lte=130" example:"33"`
-	Gender      string `json:"gender,omitempty" validate:"omitempty,max=6" example:"male"`
-	Nationalize string `json:"nationalize,omitempty" validate:"omitempty,max=3" example:"RU"`
+	Gender      string `json:"gender,omitempty" validate:"omitempty,gender" example:"male"`
+	Nationalize string `json:"nationalize,omitempty" validate:"omitempty,country" example:"RU"`
+	// Replace clears every field that is left out instead of keeping it.
+	Replace bool `json:"-" swaggerignore:"true"`
+}
+
+// ReplacedPerson is the body of PUT /api/v1/profiles/{guid}. Name and surname
+// are required; every other field that is left out is cleared.
+type ReplacedPerson struct {
+	Name        string `json:"name" validate:"required,min=1,max=20,personname" example:"Valeriy"`
+	Surname     string `json:"surname" validate:"required,min=1,max=30,personname" example:"Popov"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,min=1,max=25,personname" example:"Valentinovich"`
+	Age         int    `json:"age,omitempty" validate:"omitempty,gte=0,lte=130" example:"33"`
+	Gender      string `json:"gender,omitempty" validate:"omitempty,gender" example:"male"`
+	Nationalize string `json:"nationalize,omitempty" validate:"omitempty,country" example:"RU"`
+}
+
+// PatchedPerson is the body of PATCH /api/v1/profiles/{guid}.
+type PatchedPerson struct {
+	Name        string `json:"name,omitempty" validate:"omitempty,min=1,max=20,personname" example:"Valeriy"`
+	Surname     string `json:"surname,omitempty" validate:"omitempty,min=1,max=30,personname" example:"Popov"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,min=1,max=25,personname" example:"Valentinovich"`
+	Age         int    `json:"age,omitempty" validate:"omitempty,gte=0,lte=130" example:"33"`
+	Gender      string `json:"gender,omitempty" validate:"omitempty,gender" example:"male"`
+	Nationalize string `json:"nationalize,omitempty" validate:"omitempty,country" example:"RU"`
 }
 
 type DeletePerson struct {
 	GUID string `json:"guid" validate:"required" example:"ewqehQWE231u-Snu3h21sj-321s"`
 }
+
+const (
+	GenderSourceProvider   = "provider"
+	GenderSourcePatronymic = "patronymic"
+	GenderSourceSurname    = "surname"
+	GenderSourceManual     = "manual"
+)
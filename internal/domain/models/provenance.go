@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	// ProvenanceInferred values come from an enrichment provider or from
+	// the gender rules.
+	ProvenanceInferred = "inferred"
+	// ProvenanceManual values were set by an operator through UpdateProfile.
+	ProvenanceManual = "manual"
+	// ProvenanceImported values come from the offline dataset.
+	ProvenanceImported = "imported"
+)
+
+// FieldProvenance records where the value of an enriched field came from.
+type FieldProvenance struct {
+	Kind      string    `json:"kind"`
+	Source    string    `json:"source,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// Provenance maps FieldAge, FieldGender and FieldNationalize to the origin
+// of their current value.
+type Provenance map[string]FieldProvenance
+
+// Manual returns the fields set by an operator.
+func (p Provenance) Manual() []string {
+	var fields []string
+	for _, field := range []string{FieldAge, FieldGender, FieldNationalize} {
+		if p[field].Kind == ProvenanceManual {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
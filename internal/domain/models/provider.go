@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type ProviderStatus struct {
+	Provider         string     `json:"provider"`
+	State            string     `json:"state"`
+	Failures         int        `json:"failures"`
+	OpenedAt         *time.Time `json:"opened_at,omitempty"`
+	RateLimitedUntil *time.Time `json:"rate_limited_until,omitempty"`
+}
+
+type CacheStats struct {
+	MemoryHits uint64 `json:"memory_hits"`
+	StoreHits  uint64 `json:"store_hits"`
+	Misses     uint64 `json:"misses"`
+	Entries    int    `json:"entries"`
+}
+
+type EnrichmentStatus struct {
+	Providers []ProviderStatus `json:"providers"`
+	Cache     *CacheStats      `json:"cache,omitempty"`
+}
@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/lru"
+	"github.com/stepan41k/Effective-Mobile/internal/storage"
+)
+
+type Store interface {
+	CachedEnrichment(ctx context.Context, key string) (payload []byte, expiresAt time.Time, err error)
+	SaveCachedEnrichment(ctx context.Context, key string, payload []byte, expiresAt time.Time) (err error)
+	DeleteExpiredEnrichment(ctx context.Context) (deleted int64, err error)
+}
+
+// purgeInterval is how often a write also deletes the expired entries of
+// the store.
+const purgeInterval = time.Hour
+
+type entry struct {
+	result    enrichment.Result
+	expiresAt time.Time
+}
+
+// call is a lookup in flight. Concurrent misses on its key wait for it
+// instead of asking next again.
+type call struct {
+	done   chan struct{}
+	result enrichment.Result
+	err    error
+}
+
+// Cache is an Enricher that serves repeated names from an in-process LRU
+// backed by a durable store, and only asks next on a miss.
+type Cache struct {
+	next   enrichment.Enricher
+	store  Store
+	memory *lru.Cache[string, entry]
+	ttl    time.Duration
+	log    *slog.Logger
+
+	mu    sync.Mutex
+	calls map[string]*call
+
+	// lastPurge is the UnixNano time of the last purge of the store.
+	lastPurge atomic.Int64
+
+	memoryHits atomic.Uint64
+	storeHits  atomic.Uint64
+	misses     atomic.Uint64
+}
+
+func New(log *slog.Logger, next enrichment.Enricher, store Store, size int, ttl time.Duration) *Cache {
+	return &Cache{
+		next:   next,
+		store:  store,
+		memory: lru.New[string, entry](size),
+		ttl:    ttl,
+		log:    log,
+		calls:  make(map[string]*call),
+	}
+}
+
+func (c *Cache) Enrich(ctx context.Context, q enrichment.Query) (enrichment.Result, error) {
+	const op = "enrichment.cache.Enrich"
+
+	log := c.log.With(
+		slog.String("op", op),
+	)
+
+	key := Key(q)
+
+	if !q.Refresh {
+		if res, ok := c.cached(ctx, log, key); ok {
+			return res, nil
+		}
+	}
+
+	c.mu.Lock()
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+
+		select {
+		case <-cl.done:
+			return cl.result, cl.err
+		case <-ctx.Done():
+			return enrichment.Result{}, fmt.Errorf("%s: %w", op, ctx.Err())
+		}
+	}
+
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	cl.result, cl.err = c.lookup(ctx, log, q, key)
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	c.mu.Unlock()
+
+	close(cl.done)
+
+	if cl.err != nil {
+		return cl.result, fmt.Errorf("%s: %w", op, cl.err)
+	}
+
+	return cl.result, nil
+}
+
+// cached returns the unexpired answer for key from memory or the store.
+func (c *Cache) cached(ctx context.Context, log *slog.Logger, key string) (enrichment.Result, bool) {
+	if e, ok := c.memory.Get(key); ok {
+		if time.Now().Before(e.expiresAt) {
+			c.memoryHits.Add(1)
+
+			return e.result, true
+		}
+
+		c.memory.Remove(key)
+	}
+
+	payload, expiresAt, err := c.store.CachedEnrichment(ctx, key)
+	if err != nil {
+		if !errors.Is(err, storage.ErrCacheMiss) {
+			log.Warn("failed to read enrichment cache", sl.Err(err))
+		}
+
+		return enrichment.Result{}, false
+	}
+
+	var res enrichment.Result
+	if decodeErr := json.Unmarshal(payload, &res); decodeErr != nil {
+		log.Warn("failed to decode cached enrichment", slog.String("key", key), sl.Err(decodeErr))
+
+		return enrichment.Result{}, false
+	}
+
+	c.storeHits.Add(1)
+	c.memory.Add(key, entry{result: res, expiresAt: expiresAt})
+
+	return res, true
+}
+
+// lookup asks next and saves its answer.
+func (c *Cache) lookup(ctx context.Context, log *slog.Logger, q enrichment.Query, key string) (enrichment.Result, error) {
+	c.misses.Add(1)
+
+	res, err := c.next.Enrich(ctx, q)
+	if err != nil {
+		return res, err
+	}
+
+	expiresAt := time.Now().Add(c.ttl)
+	c.memory.Add(key, entry{result: res, expiresAt: expiresAt})
+
+	payload, err := json.Marshal(res)
+	if err != nil {
+		log.Warn("failed to encode enrichment", sl.Err(err))
+
+		return res, nil
+	}
+
+	if err := c.store.SaveCachedEnrichment(ctx, key, payload, expiresAt); err != nil {
+		log.Warn("failed to save enrichment cache", sl.Err(err))
+	}
+
+	c.purge(ctx, log)
+
+	return res, nil
+}
+
+// purge deletes the expired entries of the store, at most once per
+// purgeInterval.
+func (c *Cache) purge(ctx context.Context, log *slog.Logger) {
+	now := time.Now()
+
+	last := c.lastPurge.Load()
+	if now.Sub(time.Unix(0, last)) < purgeInterval || !c.lastPurge.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	deleted, err := c.store.DeleteExpiredEnrichment(ctx)
+	if err != nil {
+		log.Warn("failed to purge enrichment cache", sl.Err(err))
+
+		return
+	}
+
+	log.Debug("enrichment cache purged", slog.Int64("deleted", deleted))
+}
+
+func (c *Cache) Stats() models.CacheStats {
+	return models.CacheStats{
+		MemoryHits: c.memoryHits.Load(),
+		StoreHits:  c.storeHits.Load(),
+		Misses:     c.misses.Load(),
+		Entries:    c.memory.Len(),
+	}
+}
+
+// Key returns the cache key of a query. Answers differ per country hint,
+// so the hint is part of the key.
+func Key(q enrichment.Query) string {
+	return enrichment.NormalizeName(q.Name) + "|" + strings.ToUpper(q.CountryID)
+}
@@ -0,0 +1,79 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+)
+
+// Chain asks each Enricher in turn and fills the providers that failed so
+// far from the next one, so a later Enricher acts as a fallback.
+type Chain []Enricher
+
+func (c Chain) Enrich(ctx context.Context, q Query) (Result, error) {
+	var res Result
+
+	failures := map[string]error{
+		ProviderAge:         ErrProviderUnavailable,
+		ProviderGender:      ErrProviderUnavailable,
+		ProviderNationalize: ErrProviderUnavailable,
+	}
+
+	for _, e := range c {
+		r, err := e.Enrich(ctx, q)
+
+		var enrichErr *Error
+		if err != nil && !errors.As(err, &enrichErr) {
+			for provider := range failures {
+				failures[provider] = err
+			}
+
+			continue
+		}
+
+		for provider := range failures {
+			if enrichErr != nil && enrichErr.Failed(provider) {
+				for _, p := range enrichErr.Providers {
+					if p.Provider == provider {
+						failures[provider] = p.Err
+					}
+				}
+
+				continue
+			}
+
+			if p, ok := r.Provenance[provider]; ok {
+				if res.Provenance == nil {
+					res.Provenance = make(models.Provenance)
+				}
+
+				res.Provenance[provider] = p
+			}
+
+			switch provider {
+			case ProviderAge:
+				res.Age = r.Age
+			case ProviderGender:
+				res.Gender = r.Gender
+			case ProviderNationalize:
+				res.Nationalize = r.Nationalize
+			}
+
+			delete(failures, provider)
+		}
+
+		if len(failures) == 0 {
+			return res, nil
+		}
+	}
+
+	failed := &Error{}
+	for _, provider := range []string{ProviderAge, ProviderGender, ProviderNationalize} {
+		if err, ok := failures[provider]; ok {
+			failed.Providers = append(failed.Providers, &ProviderError{Provider: provider, Err: err})
+		}
+	}
+
+	return res, failed
+}
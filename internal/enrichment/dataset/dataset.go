@@ -0,0 +1,210 @@
+package dataset
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+)
+
+var ErrNameNotFound = errors.New("name not found in dataset")
+
+// Source is recorded as the provenance source of values from the dataset.
+const Source = "dataset"
+
+// Record is one name of the dataset.
+type Record struct {
+	Name              string           `json:"name"`
+	Age               int              `json:"age"`
+	AgeCount          int              `json:"age_count"`
+	Gender            string           `json:"gender"`
+	GenderProbability float32          `json:"gender_probability"`
+	GenderCount       int              `json:"gender_count"`
+	Countries         []models.Country `json:"countries"`
+}
+
+// Dataset is an Enricher backed by name statistics loaded into memory.
+type Dataset struct {
+	records map[string]Record
+}
+
+// Load reads a dataset from a .json file holding an array of records or
+// from a .csv file with the columns
+//
+//	name,age,age_count,gender,gender_probability,gender_count,countries
+//
+// where countries is a list like "RU:0.71;UA:0.12".
+func Load(path string) (*Dataset, error) {
+	const op = "enrichment.dataset.Load"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	var records []Record
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(f).Decode(&records)
+	case ".csv":
+		records, err = readCSV(f)
+	default:
+		err = fmt.Errorf("unsupported dataset format %q", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return New(records), nil
+}
+
+func New(records []Record) *Dataset {
+	d := &Dataset{records: make(map[string]Record, len(records))}
+	for _, r := range records {
+		d.records[enrichment.NormalizeName(r.Name)] = r
+	}
+
+	return d
+}
+
+func (d *Dataset) Len() int {
+	return len(d.records)
+}
+
+func (d *Dataset) Enrich(ctx context.Context, q enrichment.Query) (enrichment.Result, error) {
+	const op = "enrichment.dataset.Enrich"
+
+	var res enrichment.Result
+
+	name := q.Name
+
+	r, ok := d.records[enrichment.NormalizeName(name)]
+	if !ok {
+		return res, fmt.Errorf("%s: %w", op, &enrichment.Error{Providers: []*enrichment.ProviderError{
+			{Provider: enrichment.ProviderAge, Err: ErrNameNotFound},
+			{Provider: enrichment.ProviderGender, Err: ErrNameNotFound},
+			{Provider: enrichment.ProviderNationalize, Err: ErrNameNotFound},
+		}})
+	}
+
+	res.Age = models.Age{Name: name, Age: r.Age, Count: r.AgeCount}
+	res.Gender = models.Gender{Name: name, Gender: r.Gender, Probability: r.GenderProbability, Count: r.GenderCount}
+	res.Nationalize = models.Nationalize{Name: name, Country: r.Countries}
+	res.Provenance = make(models.Provenance)
+
+	var failed []*enrichment.ProviderError
+	found := func(provider string, ok bool) {
+		if !ok {
+			failed = append(failed, &enrichment.ProviderError{Provider: provider, Err: ErrNameNotFound})
+
+			return
+		}
+
+		res.Provenance[provider] = models.FieldProvenance{Kind: models.ProvenanceImported, Source: Source}
+	}
+
+	found(enrichment.ProviderAge, r.Age != 0)
+	found(enrichment.ProviderGender, r.Gender != "")
+	found(enrichment.ProviderNationalize, len(r.Countries) > 0)
+
+	if len(failed) > 0 {
+		return res, fmt.Errorf("%s: %w", op, &enrichment.Error{Providers: failed})
+	}
+
+	return res, nil
+}
+
+func readCSV(r io.Reader) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 7
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for i, row := range rows {
+		if i == 0 && strings.EqualFold(row[0], "name") {
+			continue
+		}
+
+		rec, err := parseRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+func parseRow(row []string) (Record, error) {
+	rec := Record{Name: row[0], Gender: row[3]}
+
+	var err error
+	if rec.Age, err = atoi(row[1]); err != nil {
+		return rec, fmt.Errorf("age: %w", err)
+	}
+	if rec.AgeCount, err = atoi(row[2]); err != nil {
+		return rec, fmt.Errorf("age_count: %w", err)
+	}
+	if rec.GenderProbability, err = atof(row[4]); err != nil {
+		return rec, fmt.Errorf("gender_probability: %w", err)
+	}
+	if rec.GenderCount, err = atoi(row[5]); err != nil {
+		return rec, fmt.Errorf("gender_count: %w", err)
+	}
+
+	for _, item := range strings.Split(row[6], ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		id, prob, ok := strings.Cut(item, ":")
+		if !ok {
+			return rec, fmt.Errorf("countries: malformed item %q", item)
+		}
+
+		p, err := atof(prob)
+		if err != nil {
+			return rec, fmt.Errorf("countries: %w", err)
+		}
+
+		rec.Countries = append(rec.Countries, models.Country{CountryID: strings.TrimSpace(id), Probability: p})
+	}
+
+	return rec, nil
+}
+
+func atoi(s string) (int, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(s)
+}
+
+func atof(s string) (float32, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 32)
+
+	return float32(f), err
+}
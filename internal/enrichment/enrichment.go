@@ -0,0 +1,119 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+)
+
+// Result holds the raw answers of the age, gender and nationality providers.
+// Query is the form of the name that was looked up, when it differs from
+// the one passed to Enrich. Provenance tells which source answered each
+// field.
+type Result struct {
+	Query       string             `json:"query,omitempty"`
+	Age         models.Age         `json:"age"`
+	Gender      models.Gender      `json:"gender"`
+	Nationalize models.Nationalize `json:"nationalize"`
+	Provenance  models.Provenance  `json:"provenance,omitempty"`
+}
+
+// Query is a name to look up. CountryID is an optional ISO 3166-1 alpha-2
+// hint that narrows the age and gender statistics to one country. Refresh
+// skips cached answers so that the providers are asked again; the new
+// answer is still cached.
+type Query struct {
+	Name      string
+	CountryID string
+	Refresh   bool
+}
+
+// Enricher looks up age, gender and nationality for a name.
+type Enricher interface {
+	Enrich(ctx context.Context, q Query) (Result, error)
+}
+
+// NormalizeName folds case and whitespace so that spelling variants of a
+// name are looked up the same way.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+const (
+	ProviderAge         = "age"
+	ProviderGender      = "gender"
+	ProviderNationalize = "nationalize"
+)
+
+// ProviderError is a failure of a single provider.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Error lists the providers that failed during one enrichment. The
+// accompanying Result still carries the answers of the other providers.
+type Error struct {
+	Providers []*ProviderError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Providers))
+	for _, p := range e.Providers {
+		msgs = append(msgs, p.Error())
+	}
+
+	return "enrichment failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, len(e.Providers))
+	for _, p := range e.Providers {
+		errs = append(errs, p)
+	}
+
+	return errs
+}
+
+// Failed reports whether the given provider is among the failed ones.
+func (e *Error) Failed(provider string) bool {
+	for _, p := range e.Providers {
+		if p.Provider == provider {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FailedProviders returns the names of the failed providers.
+func (e *Error) FailedProviders() []string {
+	names := make([]string, 0, len(e.Providers))
+	for _, p := range e.Providers {
+		names = append(names, p.Provider)
+	}
+
+	return names
+}
+
+var ErrProviderUnavailable = errors.New("provider unavailable")
+
+// RateLimitError is returned when a provider refuses calls until Until.
+type RateLimitError struct {
+	Until time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return "rate limited until " + e.Until.Format(time.RFC3339)
+}
@@ -0,0 +1,156 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxBatchSize is the most names the APIs accept in one name[] request.
+const maxBatchSize = 10
+
+type call[T any] struct {
+	name string
+	done chan struct{}
+	res  T
+	err  error
+}
+
+// batcher collects names requested concurrently and looks them up with a
+// single name[] request once size names are queued or wait has passed.
+// Names with different country hints are batched separately. size is capped
+// at maxBatchSize.
+type batcher[T any] struct {
+	provider *provider
+	size     int
+	wait     time.Duration
+
+	mu     sync.Mutex
+	groups map[string]*group[T]
+}
+
+type group[T any] struct {
+	pending []*call[T]
+	timer   *time.Timer
+}
+
+func newBatcher[T any](p *provider, size int, wait time.Duration) *batcher[T] {
+	return &batcher[T]{
+		provider: p,
+		size:     min(size, maxBatchSize),
+		wait:     wait,
+		groups:   make(map[string]*group[T]),
+	}
+}
+
+// get looks up name. countryID is passed to the provider as is and may be
+// empty.
+func (b *batcher[T]) get(ctx context.Context, name, countryID string) (T, error) {
+	if b.size <= 1 {
+		query := url.Values{paramName: {name}}
+		if countryID != "" {
+			query.Set(paramCountryID, countryID)
+		}
+
+		var v T
+		err := b.provider.fetch(ctx, query, &v)
+
+		return v, err
+	}
+
+	c := &call[T]{name: name, done: make(chan struct{})}
+
+	b.mu.Lock()
+	g, ok := b.groups[countryID]
+	if !ok {
+		g = &group[T]{}
+		b.groups[countryID] = g
+	}
+
+	g.pending = append(g.pending, c)
+
+	switch {
+	case len(g.pending) >= b.size:
+		batch := b.take(countryID)
+		go b.flush(batch, countryID)
+	case len(g.pending) == 1:
+		g.timer = time.AfterFunc(b.wait, func() {
+			b.mu.Lock()
+			batch := b.take(countryID)
+			b.mu.Unlock()
+
+			b.flush(batch, countryID)
+		})
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.res, c.err
+	case <-ctx.Done():
+		var zero T
+
+		return zero, ctx.Err()
+	}
+}
+
+// take must be called with b.mu held.
+func (b *batcher[T]) take(countryID string) []*call[T] {
+	g, ok := b.groups[countryID]
+	if !ok {
+		return nil
+	}
+
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+
+	delete(b.groups, countryID)
+
+	return g.pending
+}
+
+func (b *batcher[T]) flush(batch []*call[T], countryID string) {
+	if len(batch) == 0 {
+		return
+	}
+
+	p := b.provider
+
+	// The request is shared by several callers, so it is bounded by the
+	// provider budget rather than by any single caller's context.
+	budget := time.Duration(p.retries+1)*p.timeout + time.Duration(p.retries)*p.backoff.Max
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	index := make(map[string]int)
+	query := url.Values{}
+	for _, c := range batch {
+		if _, ok := index[c.name]; !ok {
+			index[c.name] = len(index)
+			query.Add(paramNames, c.name)
+		}
+	}
+
+	if countryID != "" {
+		query.Set(paramCountryID, countryID)
+	}
+
+	var results []T
+	err := p.fetch(ctx, query, &results)
+	if err == nil && len(results) != len(index) {
+		err = fmt.Errorf("expected %d results, got %d", len(index), len(results))
+	}
+
+	for _, c := range batch {
+		if err != nil {
+			c.err = err
+		} else {
+			c.res = results[index[c.name]]
+		}
+
+		close(c.done)
+	}
+}
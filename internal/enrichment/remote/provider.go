@@ -0,0 +1,293 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/backoff"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/breaker"
+)
+
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitRemaining = "X-Rate-Limit-Remaining"
+	headerRateLimitReset     = "X-Rate-Limit-Reset"
+)
+
+const (
+	paramName      = "name"
+	paramNames     = "name[]"
+	paramCountryID = "country_id"
+)
+
+type retryableError struct {
+	err   error
+	after time.Duration
+	// limited marks a 429 answer.
+	limited bool
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+type provider struct {
+	name    string
+	url     string
+	timeout time.Duration
+	retries int
+	backoff backoff.Backoff
+	breaker *breaker.Breaker
+	client  *http.Client
+	log     *slog.Logger
+
+	mu           sync.Mutex
+	limitedUntil time.Time
+}
+
+func (p *provider) fetch(ctx context.Context, query url.Values, v any) error {
+	for attempt := 0; ; attempt++ {
+		if until := p.rateLimitedUntil(); time.Now().Before(until) {
+			wait := time.Until(until)
+			if attempt >= p.retries || !p.canWait(ctx, wait) {
+				return &enrichment.RateLimitError{Until: until}
+			}
+
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		if err := p.breaker.Allow(); err != nil {
+			return fmt.Errorf("%w: %w", enrichment.ErrProviderUnavailable, err)
+		}
+
+		err := p.do(ctx, query, v)
+		if err == nil {
+			return nil
+		}
+
+		var retryErr *retryableError
+		if !errors.As(err, &retryErr) {
+			return err
+		}
+
+		wait := max(p.backoff.Delay(attempt), retryErr.after)
+		if attempt >= p.retries || !p.canWait(ctx, wait) {
+			// A provider still refusing calls is reported as rate limited,
+			// so the caller can postpone instead of counting a failure.
+			if retryErr.limited {
+				until := p.rateLimitedUntil()
+				if next := time.Now().Add(wait); until.Before(next) {
+					until = next
+				}
+
+				return &enrichment.RateLimitError{Until: until}
+			}
+
+			return err
+		}
+
+		p.log.Warn("retrying provider call",
+			slog.String("provider", p.name),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", wait),
+			sl.Err(err),
+		)
+
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *provider) do(ctx context.Context, query url.Values, v any) error {
+	reqCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	u, err := p.endpoint(query)
+	if err != nil {
+		p.breaker.Abort()
+
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, u, nil)
+	if err != nil {
+		p.breaker.Abort()
+
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			p.breaker.Abort()
+
+			return err
+		}
+
+		p.breaker.Failure()
+
+		return &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	p.observeRateLimit(resp.Header)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		p.breaker.Success()
+
+		after := retryAfter(resp.Header)
+		if after > 0 {
+			p.limit(time.Now().Add(after))
+		}
+
+		return &retryableError{err: fmt.Errorf("unexpected status code %d", resp.StatusCode), after: after, limited: true}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		p.breaker.Failure()
+
+		return &retryableError{err: fmt.Errorf("unexpected status code %d", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		p.breaker.Success()
+
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		p.breaker.Failure()
+
+		return &retryableError{err: fmt.Errorf("failed to decode response: %w", err)}
+	}
+
+	p.breaker.Success()
+
+	return nil
+}
+
+// endpoint merges query into the provider URL, replacing any name or
+// country parameter already present in it.
+func (p *provider) endpoint(query url.Values) (string, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Del(paramName)
+	q.Del(paramNames)
+	q.Del(paramCountryID)
+
+	for k, vs := range query {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// source names the provider in provenance records: the API host, or the
+// provider name when the URL cannot be parsed.
+func (p *provider) source() string {
+	u, err := url.Parse(p.url)
+	if err != nil || u.Host == "" {
+		return p.name
+	}
+
+	return u.Host
+}
+
+func (p *provider) observeRateLimit(h http.Header) {
+	if h.Get(headerRateLimitRemaining) != "0" {
+		return
+	}
+
+	reset, err := strconv.Atoi(h.Get(headerRateLimitReset))
+	if err != nil || reset <= 0 {
+		return
+	}
+
+	until := time.Now().Add(time.Duration(reset) * time.Second)
+	p.limit(until)
+
+	p.log.Warn("provider rate limit exhausted",
+		slog.String("provider", p.name),
+		slog.Time("until", until),
+	)
+}
+
+func (p *provider) limit(until time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if until.After(p.limitedUntil) {
+		p.limitedUntil = until
+	}
+}
+
+func (p *provider) rateLimitedUntil() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.limitedUntil
+}
+
+func (p *provider) canWait(ctx context.Context, wait time.Duration) bool {
+	if wait > p.backoff.Max {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return false
+	}
+
+	return true
+}
+
+func retryAfter(h http.Header) time.Duration {
+	if v := h.Get(headerRetryAfter); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t)
+		}
+	}
+
+	if secs, err := strconv.Atoi(h.Get(headerRateLimitReset)); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/backoff"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/breaker"
+)
+
+// Client enriches names through the agify, genderize and nationalize APIs.
+type Client struct {
+	age         *provider
+	gender      *provider
+	nationalize *provider
+
+	ages          *batcher[models.Age]
+	genders       *batcher[models.Gender]
+	nationalities *batcher[models.Nationalize]
+}
+
+func New(log *slog.Logger, cfg config.Enrichment) *Client {
+	client := &http.Client{}
+
+	newProvider := func(name, url string, timeout time.Duration) *provider {
+		log := log.With(slog.String("provider", name))
+
+		return &provider{
+			name:    name,
+			url:     url,
+			timeout: timeout,
+			retries: cfg.MaxRetries,
+			backoff: backoff.Backoff{Base: cfg.RetryBaseDelay, Max: cfg.RetryMaxDelay},
+			breaker: breaker.New(cfg.BreakerThreshold, cfg.BreakerCooldown, func(from, to breaker.State) {
+				log.Warn("circuit breaker state changed",
+					slog.String("from", from.String()),
+					slog.String("to", to.String()),
+				)
+			}),
+			client: client,
+			log:    log,
+		}
+	}
+
+	c := &Client{
+		age:         newProvider(enrichment.ProviderAge, cfg.AgeURL, cfg.AgeTimeout),
+		gender:      newProvider(enrichment.ProviderGender, cfg.GenderURL, cfg.GenderTimeout),
+		nationalize: newProvider(enrichment.ProviderNationalize, cfg.NationalizeURL, cfg.NationalizeTimeout),
+	}
+
+	c.ages = newBatcher[models.Age](c.age, cfg.BatchSize, cfg.BatchWait)
+	c.genders = newBatcher[models.Gender](c.gender, cfg.BatchSize, cfg.BatchWait)
+	c.nationalities = newBatcher[models.Nationalize](c.nationalize, cfg.BatchSize, cfg.BatchWait)
+
+	return c
+}
+
+// Enrich queries the three providers concurrently. The country hint is only
+// sent to agify and genderize; nationalize has no use for it.
+func (c *Client) Enrich(ctx context.Context, q enrichment.Query) (enrichment.Result, error) {
+	const op = "enrichment.remote.Enrich"
+
+	var (
+		res  enrichment.Result
+		wg   sync.WaitGroup
+		errs [3]error
+	)
+
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		res.Age, errs[0] = c.ages.get(ctx, q.Name, q.CountryID)
+	}()
+
+	go func() {
+		defer wg.Done()
+		res.Gender, errs[1] = c.genders.get(ctx, q.Name, q.CountryID)
+	}()
+
+	go func() {
+		defer wg.Done()
+		res.Nationalize, errs[2] = c.nationalities.get(ctx, q.Name, "")
+	}()
+
+	wg.Wait()
+
+	providers := []*provider{c.age, c.gender, c.nationalize}
+
+	res.Provenance = make(models.Provenance)
+
+	var failed []*enrichment.ProviderError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &enrichment.ProviderError{Provider: providers[i].name, Err: err})
+
+			continue
+		}
+
+		res.Provenance[providers[i].name] = models.FieldProvenance{Kind: models.ProvenanceInferred, Source: providers[i].source()}
+	}
+
+	if len(failed) > 0 {
+		return res, fmt.Errorf("%s: %w", op, &enrichment.Error{Providers: failed})
+	}
+
+	return res, nil
+}
+
+func (c *Client) Status() []models.ProviderStatus {
+	providers := []*provider{c.age, c.gender, c.nationalize}
+
+	statuses := make([]models.ProviderStatus, 0, len(providers))
+	for _, p := range providers {
+		st := p.breaker.Status()
+
+		status := models.ProviderStatus{
+			Provider: p.name,
+			State:    st.State.String(),
+			Failures: st.Failures,
+		}
+
+		if st.State != breaker.StateClosed {
+			status.OpenedAt = &st.OpenedAt
+		}
+
+		if until := p.rateLimitedUntil(); time.Now().Before(until) {
+			status.RateLimitedUntil = &until
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
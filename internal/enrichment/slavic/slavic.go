@@ -0,0 +1,105 @@
+package slavic
+
+import (
+	"strings"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/translit"
+)
+
+const (
+	genderMale   = "male"
+	genderFemale = "female"
+)
+
+type rule struct {
+	suffix string
+	gender string
+}
+
+// Suffixes are matched longest first, so "-ichna" wins over "-ich".
+var patronymicRules = []rule{
+	{"инична", genderFemale}, {"ichna", genderFemale}, {"ична", genderFemale},
+	{"овна", genderFemale}, {"евна", genderFemale}, {"ovna", genderFemale}, {"evna", genderFemale},
+	{"кызы", genderFemale}, {"kyzy", genderFemale}, {"qizi", genderFemale},
+	{"ович", genderMale}, {"евич", genderMale}, {"ovich", genderMale}, {"evich", genderMale},
+	{"ovych", genderMale}, {"evych", genderMale}, {"ич", genderMale}, {"ich", genderMale},
+	{"оглы", genderMale}, {"ogly", genderMale}, {"oglu", genderMale},
+}
+
+var surnameRules = []rule{
+	{"ская", genderFemale}, {"цкая", genderFemale}, {"skaya", genderFemale}, {"skaia", genderFemale}, {"tskaya", genderFemale},
+	{"ова", genderFemale}, {"ева", genderFemale}, {"ёва", genderFemale}, {"ина", genderFemale}, {"ына", genderFemale},
+	{"ova", genderFemale}, {"eva", genderFemale}, {"yova", genderFemale}, {"ina", genderFemale}, {"yna", genderFemale},
+	{"ский", genderMale}, {"цкий", genderMale}, {"ской", genderMale},
+	{"skiy", genderMale}, {"skii", genderMale}, {"skij", genderMale}, {"sky", genderMale}, {"skoy", genderMale},
+	{"ов", genderMale}, {"ев", genderMale}, {"ёв", genderMale}, {"ин", genderMale}, {"ын", genderMale},
+	{"ov", genderMale}, {"ev", genderMale}, {"in", genderMale}, {"yn", genderMale},
+}
+
+// slavicCountries are the countries whose surnames take the gendered
+// suffixes above. Latin suffixes such as -ina or -ov are common elsewhere
+// too (Medina, Martin), so they only apply with one of these countries.
+var slavicCountries = map[string]bool{
+	"RU": true, "UA": true, "BY": true, "KZ": true, "KG": true, "UZ": true,
+	"TJ": true, "TM": true, "AZ": true, "AM": true, "GE": true, "MD": true,
+	"BG": true, "CZ": true, "SK": true, "PL": true, "RS": true, "HR": true,
+	"BA": true, "ME": true, "MK": true, "SI": true,
+}
+
+// Inference is a gender derived from a naming rule.
+type Inference struct {
+	Gender string
+	Source string
+	Suffix string
+}
+
+// InferGender applies the patronymic rules, which are the most reliable,
+// and then the surname ones. Surname rules apply to a Cyrillic surname, or
+// to a Latin one when one of countries is Slavic. ok is false when no rule
+// matches.
+func InferGender(surname, patronymic string, countries ...string) (Inference, bool) {
+	if g, suffix, ok := match(patronymic, patronymicRules); ok {
+		return Inference{Gender: g, Source: models.GenderSourcePatronymic, Suffix: suffix}, true
+	}
+
+	if !translit.HasCyrillic(surname) && !slavic(countries) {
+		return Inference{}, false
+	}
+
+	if g, suffix, ok := match(surname, surnameRules); ok {
+		return Inference{Gender: g, Source: models.GenderSourceSurname, Suffix: suffix}, true
+	}
+
+	return Inference{}, false
+}
+
+func match(word string, rules []rule) (gender string, suffix string, ok bool) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return "", "", false
+	}
+
+	best := -1
+	for i, r := range rules {
+		if len(word) > len(r.suffix) && strings.HasSuffix(word, r.suffix) && (best < 0 || len(r.suffix) > len(rules[best].suffix)) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return "", "", false
+	}
+
+	return rules[best].gender, rules[best].suffix, true
+}
+
+func slavic(countries []string) bool {
+	for _, c := range countries {
+		if slavicCountries[strings.ToUpper(c)] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package enrichment
+
+import (
+	"context"
+
+	"github.com/stepan41k/Effective-Mobile/internal/lib/translit"
+)
+
+// Transliterator converts Cyrillic names to Latin before passing them to
+// next, and reports the form that was looked up in Result.Query.
+type Transliterator struct {
+	next   Enricher
+	scheme translit.Scheme
+}
+
+func NewTransliterator(next Enricher, scheme translit.Scheme) *Transliterator {
+	return &Transliterator{
+		next:   next,
+		scheme: scheme,
+	}
+}
+
+func (t *Transliterator) Enrich(ctx context.Context, q Query) (Result, error) {
+	if translit.HasCyrillic(q.Name) {
+		q.Name = translit.Transliterate(q.Name, t.scheme)
+	}
+
+	res, err := t.next.Enrich(ctx, q)
+	res.Query = q.Name
+
+	return res, err
+}
@@ -0,0 +1,316 @@
+package fakeenrich
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+)
+
+type Fault string
+
+const (
+	FaultNone         Fault = "none"
+	FaultLatency      Fault = "latency"
+	FaultRateLimit    Fault = "429"
+	FaultServerError  Fault = "500"
+	FaultEmptyCountry Fault = "empty_country"
+	FaultMalformed    Fault = "malformed_json"
+)
+
+var errUnknownFault = errors.New("unknown fault mode")
+
+// ParseFault parses a fault mode name. The empty string is FaultNone.
+func ParseFault(s string) (Fault, error) {
+	switch mode := Fault(s); mode {
+	case "":
+		return FaultNone, nil
+	case FaultNone, FaultLatency, FaultRateLimit, FaultServerError, FaultEmptyCountry, FaultMalformed:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w %q", errUnknownFault, s)
+	}
+}
+
+// ValidProvider reports whether provider may be used as FaultProvider.
+func ValidProvider(provider string) bool {
+	switch provider {
+	case "", "age", "gender", "nationalize":
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	PathAge         = "/age"
+	PathGender      = "/gender"
+	PathNationalize = "/nationalize"
+	PathFault       = "/_fault"
+)
+
+var countries = []string{"RU", "UA", "BY", "KZ", "US", "GB", "DE", "FR", "PL", "BG", "RS", "ES"}
+
+type Options struct {
+	Seed int64
+	// Fault is applied to every endpoint, or only to FaultProvider
+	// ("age", "gender" or "nationalize") when it is set.
+	Fault         Fault
+	FaultProvider string
+	Latency       time.Duration
+	RetryAfter    time.Duration
+	// Log receives the responses that could not be written. They are
+	// discarded when it is nil.
+	Log *slog.Logger
+}
+
+// Server mimics agify, genderize and nationalize with answers derived from
+// a hash of the seed, the name and the country_id hint, so the same query
+// always gets the same answer for a given seed.
+type Server struct {
+	mu   sync.RWMutex
+	opts Options
+	mux  *http.ServeMux
+}
+
+func New(opts Options) *Server {
+	if opts.Fault == "" {
+		opts.Fault = FaultNone
+	}
+
+	if opts.Log == nil {
+		opts.Log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc(PathAge, s.handle("age", func(name string, h uint64) any {
+		return models.Age{Name: name, Age: 18 + int(h%62), Count: 100 + int(h>>8%100000)}
+	}))
+	s.mux.HandleFunc(PathGender, s.handle("gender", func(name string, h uint64) any {
+		gender := "male"
+		if h>>16%2 == 1 {
+			gender = "female"
+		}
+
+		return models.Gender{Name: name, Gender: gender, Probability: 0.5 + float32(h>>24%50)/100, Count: 100 + int(h>>8%100000)}
+	}))
+	s.mux.HandleFunc(PathNationalize, s.handle("nationalize", func(name string, h uint64) any {
+		return models.Nationalize{Name: name, Count: 100 + int(h>>8%100000), Country: s.countries(h)}
+	}))
+	s.mux.HandleFunc(PathFault, s.handleFault)
+
+	return s
+}
+
+// NewTestServer starts the fake in-process.
+func NewTestServer(opts Options) (*httptest.Server, *Server) {
+	s := New(opts)
+
+	return httptest.NewServer(s), s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) SetFault(fault Fault, provider string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.opts.Fault = fault
+	s.opts.FaultProvider = provider
+}
+
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.opts.Latency = latency
+}
+
+func (s *Server) options() Options {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.opts
+}
+
+func (s *Server) handle(provider string, answer func(name string, h uint64) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts := s.options()
+
+		fault := FaultNone
+		if opts.FaultProvider == "" || opts.FaultProvider == provider {
+			fault = opts.Fault
+		}
+
+		if fault == FaultLatency && opts.Latency > 0 {
+			select {
+			case <-time.After(opts.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Rate-Limit-Limit", "1000")
+		w.Header().Set("X-Rate-Limit-Remaining", "1000")
+		w.Header().Set("X-Rate-Limit-Reset", "86400")
+
+		switch fault {
+		case FaultRateLimit:
+			w.Header().Set("X-Rate-Limit-Remaining", "0")
+			w.Header().Set("Retry-After", strconv.Itoa(int(opts.RetryAfter.Seconds())))
+			s.writeError(w, http.StatusTooManyRequests, "Request limit reached")
+
+			return
+		case FaultServerError:
+			s.writeError(w, http.StatusInternalServerError, "Internal server error")
+
+			return
+		case FaultMalformed:
+			if _, err := w.Write([]byte(`{"name": "`)); err != nil {
+				opts.Log.Warn("failed to write response", sl.Err(err))
+			}
+
+			return
+		}
+
+		q := r.URL.Query()
+		country := q.Get("country_id")
+
+		if names, ok := q["name[]"]; ok {
+			answers := make([]any, 0, len(names))
+			for _, name := range names {
+				answers = append(answers, s.answer(fault, name, country, answer))
+			}
+
+			s.writeJSON(w, answers)
+
+			return
+		}
+
+		if !q.Has("name") {
+			s.writeError(w, http.StatusUnprocessableEntity, "Missing 'name' parameter")
+
+			return
+		}
+
+		s.writeJSON(w, s.answer(fault, q.Get("name"), country, answer))
+	}
+}
+
+func (s *Server) answer(fault Fault, name, country string, answer func(name string, h uint64) any) any {
+	v := answer(name, s.hash(name, country))
+
+	if n, ok := v.(models.Nationalize); ok && fault == FaultEmptyCountry {
+		n.Country = []models.Country{}
+
+		return n
+	}
+
+	return v
+}
+
+func (s *Server) countries(h uint64) []models.Country {
+	start := int(h % uint64(len(countries)))
+	left := float32(0.9)
+
+	result := make([]models.Country, 0, 3)
+	for i := 0; i < 3; i++ {
+		p := left * (0.5 + float32(h>>(8*i)%40)/100)
+		left -= p
+
+		result = append(result, models.Country{
+			CountryID:   countries[(start+i)%len(countries)],
+			Probability: p,
+		})
+	}
+
+	return result
+}
+
+func (s *Server) hash(name, country string) uint64 {
+	h := fnv.New64a()
+
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], uint64(s.options().Seed))
+	h.Write(seed[:])
+	h.Write([]byte(strings.ToLower(name)))
+
+	if country != "" {
+		h.Write([]byte{0})
+		h.Write([]byte(strings.ToUpper(country)))
+	}
+
+	return h.Sum64()
+}
+
+// handleFault switches the fault mode at runtime:
+//
+//	POST /_fault?mode=500&provider=age&latency=2s
+func (s *Server) handleFault(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+
+		return
+	}
+
+	q := r.URL.Query()
+
+	mode, err := ParseFault(q.Get("mode"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "unknown fault mode")
+
+		return
+	}
+
+	provider := q.Get("provider")
+	if !ValidProvider(provider) {
+		s.writeError(w, http.StatusBadRequest, "unknown provider")
+
+		return
+	}
+
+	if v := q.Get("latency"); v != "" {
+		latency, err := time.ParseDuration(v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid latency")
+
+			return
+		}
+
+		s.SetLatency(latency)
+	}
+
+	s.SetFault(mode, provider)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	s.writeJSON(w, map[string]string{"error": msg})
+}
+
+// writeJSON encodes v as the response body. The status line is sent by
+// then, so a failure can only be logged.
+func (s *Server) writeJSON(w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.options().Log.Warn("failed to write response", sl.Err(err))
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	resp "github.com/stepan41k/Effective-Mobile/internal/lib/api/response"
+)
+
+type Providers interface {
+	Status() []models.ProviderStatus
+}
+
+type Cache interface {
+	Stats() models.CacheStats
+}
+
+type EnrichmentHandler struct {
+	providers Providers
+	cache     Cache
+	log       *slog.Logger
+}
+
+func New(providers Providers, cache Cache, log *slog.Logger) *EnrichmentHandler {
+	return &EnrichmentHandler{
+		providers: providers,
+		cache:     cache,
+		log:       log,
+	}
+}
+
+// @Summary Status
+// @Tags enrichment
+// @Description Outputs circuit breaker and rate limit state of every enrichment provider and cache counters
+// @ID enrichment-status
+// @Produce  json
+// @Success 200 {object} response.SuccessResponse
+// @Router /enrichment/status [get]
+func (m *EnrichmentHandler) Status() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.enrichment.Status"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		status := models.EnrichmentStatus{
+			Providers: m.providers.Status(),
+		}
+
+		if m.cache != nil {
+			stats := m.cache.Stats()
+			status.Cache = &stats
+		}
+
+		log.Debug("got enrichment status")
+
+		render.JSON(w, r, resp.SuccessResponse{
+			Status: http.StatusOK,
+			Data:   status,
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+	resp "github.com/stepan41k/Effective-Mobile/internal/lib/api/response"
+	"github.com/stepan41k/Effective-Mobile/internal/service"
+)
+
+// problems maps the errors handlers can get from the service to problem
+// responses. The first match wins; anything else is an internal error.
+var problems = []struct {
+	err    error
+	status int
+	kind   string
+	title  string
+	detail func(err error) string
+}{
+	{service.ErrProfileNotFound, http.StatusNotFound, "profile-not-found", "Profile not found", fixed("profile not found")},
+	{service.ErrNoChanges, http.StatusConflict, "no-changes", "Nothing to update", fixed("nothing to update")},
+	{service.ErrEnrichmentFailed, http.StatusServiceUnavailable, "enrichment-unavailable", "Enrichment unavailable", enrichmentDetail},
+	{filter.ErrInvalid, http.StatusBadRequest, "invalid-query", "Invalid query", filterDetail},
+}
+
+// problemOf translates err into the problem the client gets.
+func problemOf(err error) resp.Problem {
+	for _, p := range problems {
+		if errors.Is(err, p.err) {
+			return resp.NewProblem(p.status, p.kind, p.title, p.detail(err))
+		}
+	}
+
+	return resp.NewProblem(http.StatusInternalServerError, "internal", "Internal error", "internal error")
+}
+
+// renderProblem logs err and responds with its problem. Client errors are
+// logged as warnings.
+func renderProblem(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	p := problemOf(err)
+
+	if p.Status >= http.StatusInternalServerError {
+		log.Error(p.Detail, sl.Err(err))
+	} else {
+		log.Warn(p.Detail, sl.Err(err))
+	}
+
+	writeProblem(w, r, p)
+}
+
+// decodeProblem is the problem of a request body that could not be read.
+func decodeProblem(err error) resp.Problem {
+	if errors.Is(err, io.EOF) {
+		return resp.NewProblem(http.StatusBadRequest, "invalid-body", "Invalid request body", "empty request")
+	}
+
+	return resp.NewProblem(http.StatusBadRequest, "invalid-body", "Invalid request body", "failed to decode request")
+}
+
+// writeProblem sets the request ID as the problem instance and writes it.
+func writeProblem(w http.ResponseWriter, r *http.Request, p resp.Problem) {
+	p.Instance = middleware.GetReqID(r.Context())
+
+	resp.WriteProblem(w, p)
+}
+
+func fixed(detail string) func(error) string {
+	return func(error) string {
+		return detail
+	}
+}
+
+// enrichmentDetail names the failed providers.
+func enrichmentDetail(err error) string {
+	msg := "failed to enrich profile"
+
+	var enrichErr *enrichment.Error
+	if errors.As(err, &enrichErr) {
+		msg += ": " + strings.Join(enrichErr.FailedProviders(), ", ")
+	}
+
+	return msg
+}
+
+// filterDetail drops the operation prefixes wrapped around a filter error.
+func filterDetail(err error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, filter.ErrInvalid.Error()); i >= 0 {
+		return msg[i:]
+	}
+
+	return msg
+}
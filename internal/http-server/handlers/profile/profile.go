@@ -2,26 +2,26 @@ package handlers
 
 import (
 	"context"
-	"errors"
-	"io"
 	"log/slog"
 	"net/http"
-	"os"
 
+	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
 	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
 	resp "github.com/stepan41k/Effective-Mobile/internal/lib/api/response"
-	"github.com/stepan41k/Effective-Mobile/internal/service"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/validate"
 )
 
 type Profile interface {
-	TakeProfiles(ctx context.Context, profile models.GetPerson) (profiles []models.Person, err error)
+	TakeProfiles(ctx context.Context, profile models.GetPerson) (page models.ProfilePage, err error)
+	TakeProfile(ctx context.Context, guid string) (profile models.Person, err error)
 	RemoveProfile(ctx context.Context, profile models.DeletePerson) (guid []byte, err error)
 	UpdateProfile(ctx context.Context, profile models.UpdatedPerson) (guid []byte, err error)
-	NewProfile(ctx context.Context, profile models.EnrichedPerson) (guid []byte, err error)
+	NewProfile(ctx context.Context, profile models.NewPerson) (guid []byte, err error)
+	ReenrichProfile(ctx context.Context, guid string) (result models.ReenrichResult, err error)
 }
 
 type ProfileHandler struct {
@@ -36,12 +36,6 @@ func New(profile Profile, log *slog.Logger) *ProfileHandler {
 	}
 }
 
-const (
-	EnvAge = "AGE_API"
-	EnvGender = "GENDER_API"
-	EnvNationalize = "NATIONALIZE_API"
-)
-
 // @Summary Get
 // @Tags profile
 // @Description Accepts filters and outputs profiles based on them
@@ -49,11 +43,12 @@ const (
 // @Accept  json
 // @Produce  json
 // @Param input body models.GetPerson true "page and size of page is necessary"
-// @Success 200 {object} response.SuccessResponse
-// @Failure 409 {object} response.ErrorResponse
-// @Failure 500 {object} response.ErrorResponse
-// @Failure default {object} response.ErrorResponse
-// @Router /get [post]
+// @Success 200 {object} response.ListResponse
+// @Failure 400,422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure default {object} response.Problem
+// @Deprecated
+// @Router /profile/take [post]
 func (m *ProfileHandler) TakeProfiles(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "http.handlers.profile.TakeProfile"
@@ -71,34 +66,47 @@ func (m *ProfileHandler) TakeProfiles(ctx context.Context) http.HandlerFunc {
 			return
 		}
 
-		profiles, err := m.profile.TakeProfiles(ctx, req)
+		page, err := m.profile.TakeProfiles(ctx, req)
 		if err != nil {
-			if errors.Is(err, service.ErrProfilesNotFound) {
-				log.Warn("profiles not found")
+			renderProblem(w, r, log, err)
 
-				render.Status(r, http.StatusConflict)
+			return
+		}
 
-				render.JSON(w, r, resp.ErrorResponse{
-					Status: http.StatusConflict,
-					Error:  "profiles not found",
-				})
-			}
+		render.JSON(w, r, listResponse(page, req.PageSize))
+	}
+}
 
-			log.Error("internal error")
+// @Summary Get by GUID
+// @Tags profile
+// @Description Outputs a single profile with its GUID and timestamps
+// @ID get-profile
+// @Produce  json
+// @Param guid path string true "profile GUID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure default {object} response.Problem
+// @Router /api/v1/profiles/{guid} [get]
+func (m *ProfileHandler) TakeProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.TakeProfile"
 
-			render.Status(r, http.StatusInternalServerError)
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
 
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "internal error",
-			})
+		profile, err := m.profile.TakeProfile(r.Context(), chi.URLParam(r, "guid"))
+		if err != nil {
+			renderProblem(w, r, log, err)
 
 			return
 		}
 
 		render.JSON(w, r, resp.SuccessResponse{
 			Status: http.StatusOK,
-			Data:   profiles,
+			Data:   profile,
 		})
 	}
 }
@@ -111,10 +119,11 @@ func (m *ProfileHandler) TakeProfiles(ctx context.Context) http.HandlerFunc {
 // @Produce  json
 // @Param input body models.DeletePerson true "GUID is necessary"
 // @Success 200 {object} response.SuccessResponse
-// @Failure 409 {object} response.ErrorResponse
-// @Failure 500 {object} response.ErrorResponse
-// @Failure default {object} response.ErrorResponse
-// @Router /delete [delete]
+// @Failure 400,404,422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure default {object} response.Problem
+// @Deprecated
+// @Router /profile/remove [delete]
 func (m *ProfileHandler) RemoveProfile(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "http.handlers.music.RemoveProfile"
@@ -134,26 +143,7 @@ func (m *ProfileHandler) RemoveProfile(ctx context.Context) http.HandlerFunc {
 
 		guid, err := m.profile.RemoveProfile(ctx, req)
 		if err != nil {
-			if errors.Is(err, service.ErrProfileNotFound) {
-				log.Warn("profile not found")
-
-				render.Status(r, http.StatusConflict)
-
-				render.JSON(w, r, resp.ErrorResponse{
-					Status: http.StatusConflict,
-					Error:  "profile not found",
-				})
-
-				return
-			}
-			log.Error("internal error")
-
-			render.Status(r, http.StatusInternalServerError)
-
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "internal error",
-			})
+			renderProblem(w, r, log, err)
 
 			return
 		}
@@ -173,10 +163,11 @@ func (m *ProfileHandler) RemoveProfile(ctx context.Context) http.HandlerFunc {
 // @Produce  json
 // @Param input body models.UpdatedPerson true "GUID is necessary"
 // @Success 200 {object} response.SuccessResponse
-// @Failure 400,409 {object} response.ErrorResponse
-// @Failure 500 {object} response.ErrorResponse
-// @Failure default {object} response.ErrorResponse
-// @Router /update [patch]
+// @Failure 400,404,409,422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure default {object} response.Problem
+// @Deprecated
+// @Router /profile/update [patch]
 func (m *ProfileHandler) UpdateProfile(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "http.handlers.music.UpdateProfile"
@@ -196,40 +187,7 @@ func (m *ProfileHandler) UpdateProfile(ctx context.Context) http.HandlerFunc {
 
 		guid, err := m.profile.UpdateProfile(ctx, req)
 		if err != nil {
-			if errors.Is(err, service.ErrProfileNotFound) {
-				log.Warn("profile not found")
-
-				render.Status(r, http.StatusConflict)
-
-				render.JSON(w, r, resp.ErrorResponse{
-					Status: http.StatusConflict,
-					Error:  "profile not found",
-				})
-
-				return
-			}
-
-			if errors.Is(err, service.ErrNoChanges) {
-				log.Warn("nothing to update or profile not exists")
-
-				render.Status(r, http.StatusConflict)
-
-				render.JSON(w, r, resp.ErrorResponse{
-					Status: http.StatusConflict,
-					Error:  "nothing to update or profile not exists",
-				})
-
-				return
-			}
-
-			log.Error("internal error", sl.Err(err))
-
-			render.Status(r, http.StatusInternalServerError)
-
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "internal error",
-			})
+			renderProblem(w, r, log, err)
 
 			return
 		}
@@ -249,10 +207,11 @@ func (m *ProfileHandler) UpdateProfile(ctx context.Context) http.HandlerFunc {
 // @Produce  json
 // @Param input body models.NewPerson true "name and surname is necessary"
 // @Success 200 {object} response.SuccessResponse
-// @Failure 400,409 {object} response.ErrorResponse
-// @Failure 500 {object} response.ErrorResponse
-// @Failure default {object} response.ErrorResponse
-// @Router /create [post]
+// @Failure 400,422 {object} response.Problem
+// @Failure 500,503 {object} response.Problem
+// @Failure default {object} response.Problem
+// @Deprecated
+// @Router /profile/new [post]
 func (m *ProfileHandler) NewProfile(ctx context.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "http.handlers.music.NewProfile"
@@ -263,7 +222,6 @@ func (m *ProfileHandler) NewProfile(ctx context.Context) http.HandlerFunc {
 		)
 
 		var req models.NewPerson
-		var profile models.EnrichedPerson
 
 		err := render.Decode(r, &req)
 		flag := CheckForErrors(req, w, r, log, err)
@@ -271,121 +229,73 @@ func (m *ProfileHandler) NewProfile(ctx context.Context) http.HandlerFunc {
 			return
 		}
 
-		var age models.Age
-		ageReq, err := http.Get(os.Getenv(EnvAge) + req.Name)
-		if err != nil {
-			log.Error("failed to get age")
-
-			render.Status(r, http.StatusInternalServerError)
-
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "failed to get age",
-			})
-
-			return
-		}
-
-		render.DecodeJSON(ageReq.Body, &age)
-		profile.Age = age.Age
-
-		var gender models.Gender
-		genderReq, err := http.Get(os.Getenv(EnvGender) + req.Name)
+		guid, err := m.profile.NewProfile(r.Context(), req)
 		if err != nil {
-			log.Error("failed to get gender")
-
-			render.Status(r, http.StatusInternalServerError)
-
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "failed to get gender",
-			})
+			renderProblem(w, r, log, err)
 
 			return
 		}
 
-		render.DecodeJSON(genderReq.Body, &gender)
-		profile.Gender = gender.Gender
-
-		var nationalize models.Nationalize
-		nationalizeReq, err := http.Get(os.Getenv(EnvNationalize) + req.Name)
-		if err != nil {
-			log.Error("failed to get nationalize")
-
-			render.Status(r, http.StatusInternalServerError)
-
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "failed to get nationalize",
-			})
-
-			return
-		}
+		render.JSON(w, r, resp.SuccessResponse{
+			Status: http.StatusOK,
+			Data:   string(guid),
+		})
+	}
+}
 
-		render.DecodeJSON(nationalizeReq.Body, &nationalize)
-		profile.Nationalize = nationalize.Country[0].CountryID
+// @Summary Re-enrich
+// @Tags profile
+// @Description Looks the profile up again and refreshes age, gender and nationality. Fields set manually are skipped
+// @ID reenrich-profile
+// @Produce  json
+// @Param guid path string true "profile GUID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.Problem
+// @Failure 500,503 {object} response.Problem
+// @Failure default {object} response.Problem
+// @Router /api/v1/profiles/{guid}/enrich [post]
+func (m *ProfileHandler) ReenrichProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.ReenrichProfile"
 
-		profile.GUID = req.GUID
-		profile.Name = req.Name
-		profile.Surname = req.Surname
-		profile.Patronymic = req.Patronymic
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
 
-		log.Info(profile.Name, profile.Surname, profile.Patronymic, profile.Gender, profile.Nationalize)
+		guid := chi.URLParam(r, "guid")
 
-		guid, err := m.profile.NewProfile(ctx, profile)
+		result, err := m.profile.ReenrichProfile(r.Context(), guid)
 		if err != nil {
-			log.Error("internal error", sl.Err(err))
-
-			render.Status(r, http.StatusInternalServerError)
-
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusInternalServerError,
-				Error:  "internal error",
-			})
+			renderProblem(w, r, log, err)
 
 			return
 		}
 
 		render.JSON(w, r, resp.SuccessResponse{
 			Status: http.StatusOK,
-			Data:   string(guid),
+			Data:   result,
 		})
 	}
 }
 
 func CheckForErrors(req any, w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) bool {
-
 	if err != nil {
-		if errors.Is(err, io.EOF) {
-			log.Error("request body is empty")
-
-			render.Status(r, http.StatusConflict)
+		log.Warn("failed to decode request", sl.Err(err))
 
-			render.JSON(w, r, resp.ErrorResponse{
-				Status: http.StatusConflict,
-				Error:  "empty request",
-			})
-
-			return true
-		}
+		writeProblem(w, r, decodeProblem(err))
 
-		log.Error("failed to decode request")
-
-		render.Status(r, http.StatusBadRequest)
-
-		render.JSON(w, r, resp.ErrorResponse{
-			Status: http.StatusBadRequest,
-			Error:  "failed to decode request",
-		})
 		return true
 	}
 
-	if err := validator.New().Struct(req); err != nil {
+	if err := validate.Struct(req); err != nil {
 		validateErr := err.(validator.ValidationErrors)
 
-		log.Error("invalid request", sl.Err(err))
+		log.Warn("invalid request", sl.Err(err))
+
+		trans := validate.Translator(r.Header.Get("Accept-Language"))
 
-		render.JSON(w, r, resp.ValidationError(validateErr))
+		writeProblem(w, r, resp.ValidationProblem(validateErr, trans))
 
 		return true
 	}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
+	resp "github.com/stepan41k/Effective-Mobile/internal/lib/api/response"
+)
+
+// ResourcePath is the base path of the versioned profiles resource.
+const ResourcePath = "/api/v1/profiles"
+
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+// @Summary List
+// @Tags profiles
+// @Description Outputs profiles matching the query-string filters, e.g. age[gte]=20&age[lt]=40&gender[in]=male,female
+// @ID list-profiles
+// @Produce  json
+// @Param name query string false "name, also name[ne|in|prefix|contains]"
+// @Param surname query string false "surname, also surname[ne|in|prefix|contains]"
+// @Param patronymic query string false "patronymic, also patronymic[ne|in|prefix|contains]"
+// @Param age query int false "age, also age[ne|gt|gte|lt|lte|in]"
+// @Param gender query string false "gender, also gender[ne|in|prefix|contains]"
+// @Param nationalize query string false "nationality, also nationalize[ne|in|prefix|contains]"
+// @Param gender_probability query number false "gender probability, also gender_probability[ne|gt|gte|lt|lte|in]"
+// @Param country_probability query number false "probability of the top country, also country_probability[ne|gt|gte|lt|lte|in]"
+// @Param sort query string false "comma-separated sort keys, - for descending, e.g. surname,-age"
+// @Param page query int false "page, 1 by default"
+// @Param page_size query int false "page size from 1 to 100, 10 by default"
+// @Param cursor query string false "next_cursor or prev_cursor of an earlier page, used instead of page"
+// @Success 200 {object} response.ListResponse
+// @Failure 400,422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /api/v1/profiles [get]
+func (m *ProfileHandler) ListProfiles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.ListProfiles"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, err := decodeFilter(r.URL.Query())
+		if err != nil {
+			log.Warn("failed to decode query", sl.Err(err))
+
+			writeProblem(w, r, resp.NewProblem(http.StatusBadRequest, "invalid-query", "Invalid query", filterDetail(err)))
+
+			return
+		}
+
+		if CheckForErrors(filter, w, r, log, nil) {
+			return
+		}
+
+		page, err := m.profile.TakeProfiles(r.Context(), filter)
+		if err != nil {
+			renderProblem(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, listResponse(page, filter.PageSize))
+	}
+}
+
+// @Summary Create
+// @Tags profiles
+// @Description Creates a profile and points to it in the Location header
+// @ID create-profile-v1
+// @Accept  json
+// @Produce  json
+// @Param input body models.NewPerson true "name and surname is necessary"
+// @Success 201 {object} response.SuccessResponse
+// @Failure 400,422 {object} response.Problem
+// @Failure 500,503 {object} response.Problem
+// @Router /api/v1/profiles [post]
+func (m *ProfileHandler) CreateProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.CreateProfile"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req models.NewPerson
+
+		err := render.Decode(r, &req)
+		if CheckForErrors(req, w, r, log, err) {
+			return
+		}
+
+		guid, err := m.profile.NewProfile(r.Context(), req)
+		if err != nil {
+			renderProblem(w, r, log, err)
+
+			return
+		}
+
+		w.Header().Set("Location", ResourcePath+"/"+url.PathEscape(string(guid)))
+
+		render.Status(r, http.StatusCreated)
+
+		render.JSON(w, r, resp.SuccessResponse{
+			Status: http.StatusCreated,
+			Data:   string(guid),
+		})
+	}
+}
+
+// @Summary Replace
+// @Tags profiles
+// @Description Replaces the profile. Enriched fields that are sent become manual overrides; fields that are left out, patronymic included, are cleared
+// @ID replace-profile
+// @Accept  json
+// @Produce  json
+// @Param guid path string true "profile GUID"
+// @Param input body models.ReplacedPerson true "name and surname is necessary"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400,404,409,422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /api/v1/profiles/{guid} [put]
+func (m *ProfileHandler) ReplaceProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.ReplaceProfile"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req models.ReplacedPerson
+
+		err := render.Decode(r, &req)
+		if CheckForErrors(req, w, r, log, err) {
+			return
+		}
+
+		m.updateProfile(w, r, log, models.UpdatedPerson{
+			GUID:        chi.URLParam(r, "guid"),
+			Name:        req.Name,
+			Surname:     req.Surname,
+			Patronymic:  req.Patronymic,
+			Age:         req.Age,
+			Gender:      req.Gender,
+			Nationalize: req.Nationalize,
+			Replace:     true,
+		})
+	}
+}
+
+// @Summary Patch
+// @Tags profiles
+// @Description Updates the fields that are sent. Enriched fields become manual overrides
+// @ID patch-profile
+// @Accept  json
+// @Produce  json
+// @Param guid path string true "profile GUID"
+// @Param input body models.PatchedPerson true "at least one field is necessary"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400,404,409,422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /api/v1/profiles/{guid} [patch]
+func (m *ProfileHandler) PatchProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.PatchProfile"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req models.PatchedPerson
+
+		err := render.Decode(r, &req)
+		if CheckForErrors(req, w, r, log, err) {
+			return
+		}
+
+		m.updateProfile(w, r, log, models.UpdatedPerson{
+			GUID:        chi.URLParam(r, "guid"),
+			Name:        req.Name,
+			Surname:     req.Surname,
+			Patronymic:  req.Patronymic,
+			Age:         req.Age,
+			Gender:      req.Gender,
+			Nationalize: req.Nationalize,
+		})
+	}
+}
+
+// @Summary Delete
+// @Tags profiles
+// @Description Removes the profile
+// @ID delete-profile-v1
+// @Param guid path string true "profile GUID"
+// @Success 204
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /api/v1/profiles/{guid} [delete]
+func (m *ProfileHandler) DeleteProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.profile.DeleteProfile"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		_, err := m.profile.RemoveProfile(r.Context(), models.DeletePerson{GUID: chi.URLParam(r, "guid")})
+		if err != nil {
+			renderProblem(w, r, log, err)
+
+			return
+		}
+
+		render.NoContent(w, r)
+	}
+}
+
+// updateProfile applies the update and responds with the updated profile.
+func (m *ProfileHandler) updateProfile(w http.ResponseWriter, r *http.Request, log *slog.Logger, req models.UpdatedPerson) {
+	_, err := m.profile.UpdateProfile(r.Context(), req)
+	if err != nil {
+		renderProblem(w, r, log, err)
+
+		return
+	}
+
+	profile, err := m.profile.TakeProfile(r.Context(), req.GUID)
+	if err != nil {
+		renderProblem(w, r, log, err)
+
+		return
+	}
+
+	render.JSON(w, r, resp.SuccessResponse{
+		Status: http.StatusOK,
+		Data:   profile,
+	})
+}
+
+// decodeFilter reads the filter language, sorting and paging from a query
+// string.
+// Paging defaults to the first page of defaultPageSize profiles.
+func decodeFilter(q url.Values) (models.GetPerson, error) {
+	req := models.GetPerson{
+		Page:     defaultPage,
+		PageSize: defaultPageSize,
+	}
+
+	for key, dst := range map[string]*int{"page": &req.Page, "page_size": &req.PageSize} {
+		if !q.Has(key) {
+			continue
+		}
+
+		v, err := strconv.Atoi(q.Get(key))
+		if err != nil {
+			return req, fmt.Errorf("parameter %s must be an integer", key)
+		}
+
+		*dst = v
+	}
+
+	and, err := filter.Parse(q)
+	if err != nil {
+		return req, err
+	}
+
+	req.Filter = and
+
+	req.Sort, err = filter.ParseSort(q.Get("sort"))
+	if err != nil {
+		return req, err
+	}
+
+	if q.Has("cursor") {
+		req.Cursor, err = filter.DecodeCursor(q.Get("cursor"))
+		if err != nil {
+			return req, err
+		}
+	}
+
+	return req, nil
+}
+
+// listResponse renders a page of profiles with its metadata. An empty page
+// is an empty array rather than null.
+func listResponse(page models.ProfilePage, pageSize int) resp.ListResponse {
+	if page.Profiles == nil {
+		page.Profiles = []models.Person{}
+	}
+
+	return resp.ListResponse{
+		Status: http.StatusOK,
+		Data:   page.Profiles,
+		Meta: resp.ListMeta{
+			Total:          page.Total,
+			TotalEstimated: page.TotalEstimated,
+			Page:           page.Page,
+			PageSize:       pageSize,
+			HasMore:        page.HasMore,
+		},
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+}
@@ -0,0 +1,25 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// New marks every response as deprecated since the given time (RFC 9745)
+// and links to the successor resource.
+func New(since time.Time, successor string) func(next http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	link := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Add("Link", link)
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
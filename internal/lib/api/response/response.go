@@ -1,45 +1,103 @@
 package response
 
 import (
-	"fmt"
+	"encoding/json"
 	"net/http"
 	"strings"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/validate"
 )
 
-type ErrorResponse struct {
-	Status int    `json:"status"`
-	Error  string `json:"error"`
-}
-
 type SuccessResponse struct {
 	Status int `json:"status"`
 	Data   any `json:"data"`
 }
 
-func ValidationError(errs validator.ValidationErrors) ErrorResponse {
-	var errMsgs []string
+type ListResponse struct {
+	Status     int      `json:"status"`
+	Data       any      `json:"data"`
+	Meta       ListMeta `json:"meta"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	PrevCursor string   `json:"prev_cursor,omitempty"`
+}
+
+// ListMeta describes the page of a list response. Page is omitted for
+// cursor pages, and total_estimated is set when Total is an estimate.
+type ListMeta struct {
+	Total          int  `json:"total"`
+	TotalEstimated bool `json:"total_estimated,omitempty"`
+	Page           int  `json:"page,omitempty"`
+	PageSize       int  `json:"page_size"`
+	HasMore        bool `json:"has_more"`
+}
 
-	for _, err := range errs {
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
-		case "min":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s must have more than %s characters", err.Field(), err.Param()))
-		case "max":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s must have less than %s characters", err.Field(), err.Param()))
-		case "gte":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s must have greater than %s", err.Field(), err.Param()))
-		case "lte":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s must have less than %s", err.Field(), err.Param()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
-		}
+// ProblemContentType is the media type of Problem bodies (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBase prefixes the type URIs of the problems this API reports.
+const ProblemTypeBase = "/problems/"
+
+// Problem is an RFC 7807 problem details body. Instance holds the request
+// ID, and Errors lists the failed fields of a validation problem.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a failed validation rule. Field is the JSON name of the
+// field, and JSONPointer locates it in the request body.
+type FieldError struct {
+	Field       string `json:"field"`
+	JSONPointer string `json:"json_pointer"`
+	Rule        string `json:"rule"`
+	Param       string `json:"param,omitempty"`
+	Message     string `json:"message"`
+}
+
+func NewProblem(status int, kind, title, detail string) Problem {
+	return Problem{
+		Type:   ProblemTypeBase + kind,
+		Title:  title,
+		Status: status,
+		Detail: detail,
 	}
+}
+
+// WriteProblem writes p with its status and the problem media type.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+
+	_ = json.NewEncoder(w).Encode(p)
+}
 
-	return ErrorResponse{
-		Status: http.StatusBadRequest,
-		Error:  strings.Join(errMsgs, ", "),
+// ValidationProblem lists the failed fields with messages in the language
+// of trans.
+func ValidationProblem(errs validator.ValidationErrors, trans ut.Translator) Problem {
+	msgs := make([]string, 0, len(errs))
+	fields := make([]FieldError, 0, len(errs))
+
+	for _, err := range errs {
+		msg := err.Translate(trans)
+
+		msgs = append(msgs, msg)
+		fields = append(fields, FieldError{
+			Field:       err.Field(),
+			JSONPointer: validate.JSONPointer(err),
+			Rule:        err.Tag(),
+			Param:       err.Param(),
+			Message:     msg,
+		})
 	}
+
+	p := NewProblem(http.StatusUnprocessableEntity, "validation", "Invalid request", strings.Join(msgs, ", "))
+	p.Errors = fields
+
+	return p
 }
@@ -0,0 +1,200 @@
+// Package validate checks request bodies and translates the failures for
+// the languages of an Accept-Language header.
+package validate
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	ruTranslations "github.com/go-playground/validator/v10/translations/ru"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/iso3166"
+)
+
+var (
+	validate = validator.New()
+	uni      = ut.New(en.New(), en.New(), ru.New())
+)
+
+// nameSeparators may join the letters of a name, as in Jean-Luc or O'Neil.
+const nameSeparators = "-'’"
+
+func init() {
+	// Failures report the JSON names of the fields, as clients send them.
+	// An empty name falls back to the Go name.
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	// gender mirrors the gen enum, which rejects anything else with a 500.
+	validate.RegisterAlias("gender", "oneof="+strings.Join(models.Genders, " "))
+
+	if err := validate.RegisterValidation("country", country); err != nil {
+		panic(err)
+	}
+
+	if err := validate.RegisterValidation("personname", personName); err != nil {
+		panic(err)
+	}
+
+	enTrans, _ := uni.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		panic(err)
+	}
+
+	ruTrans, _ := uni.GetTranslator("ru")
+	if err := ruTranslations.RegisterDefaultTranslations(validate, ruTrans); err != nil {
+		panic(err)
+	}
+
+	translations := []struct {
+		trans ut.Translator
+		tag   string
+		text  string
+	}{
+		// The ru translations lack required_without.
+		{ruTrans, "required_without", "{0} обязательное поле"},
+		// The oneof translations look an alias up by its own tag.
+		{enTrans, "gender", "{0} must be one of [{1}]"},
+		{ruTrans, "gender", "{0} должен быть одним из [{1}]"},
+		{enTrans, "country", "{0} must be an ISO 3166-1 alpha-2 country code"},
+		{ruTrans, "country", "{0} должен быть кодом страны ISO 3166-1 alpha-2"},
+		{enTrans, "personname", "{0} may contain only letters, hyphens and apostrophes"},
+		{ruTrans, "personname", "{0} может содержать только буквы, дефисы и апострофы"},
+	}
+
+	for _, t := range translations {
+		if err := register(t.trans, t.tag, t.text); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Struct validates the fields of s.
+func Struct(s any) error {
+	return validate.Struct(s)
+}
+
+// Translator picks the translator for the most preferred supported
+// language of an Accept-Language header, English by default.
+func Translator(acceptLanguage string) ut.Translator {
+	trans, _ := uni.FindTranslator(languages(acceptLanguage)...)
+
+	return trans
+}
+
+// JSONPointer returns the RFC 6901 pointer to the failed field within the
+// request body, e.g. /countries/0/country_id.
+func JSONPointer(err validator.FieldError) string {
+	// The namespace starts with the Go name of the validated struct.
+	_, path, _ := strings.Cut(err.Namespace(), ".")
+
+	var b strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		name, index, indexed := strings.Cut(part, "[")
+
+		b.WriteString("/" + escapePointer(name))
+
+		if indexed {
+			b.WriteString("/" + escapePointer(strings.TrimSuffix(index, "]")))
+		}
+	}
+
+	return b.String()
+}
+
+// country accepts assigned ISO 3166-1 alpha-2 codes.
+func country(fl validator.FieldLevel) bool {
+	return iso3166.Valid(fl.Field().String())
+}
+
+// personName accepts Unicode letters joined by nameSeparators. Combining
+// marks are allowed after the first letter.
+func personName(fl validator.FieldLevel) bool {
+	for i, r := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(r):
+		case i > 0 && (unicode.Is(unicode.Mn, r) || strings.ContainsRune(nameSeparators, r)):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func register(trans ut.Translator, tag, text string) error {
+	return validate.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+		return ut.Add(tag, text, false)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		msg, err := ut.T(tag, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+
+		return msg
+	})
+}
+
+// languages lists the primary subtags of an Accept-Language header by
+// descending quality.
+func languages(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		primary, _, _ := strings.Cut(tag, "-")
+		if primary == "" || primary == "*" || q <= 0 {
+			continue
+		}
+
+		langs = append(langs, lang{tag: strings.ToLower(primary), q: q})
+	}
+
+	slices.SortStableFunc(langs, func(a, b lang) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+
+		return 0
+	})
+
+	tags := make([]string, 0, len(langs))
+	for _, l := range langs {
+		tags = append(tags, l.tag)
+	}
+
+	return tags
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
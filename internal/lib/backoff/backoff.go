@@ -0,0 +1,30 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes exponential delays with full jitter.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns a random delay in [0, min(Max, Base*2^attempt)].
+func (b Backoff) Delay(attempt int) time.Duration {
+	ceil := b.Base
+	for i := 0; i < attempt && ceil < b.Max; i++ {
+		ceil *= 2
+	}
+
+	if ceil > b.Max {
+		ceil = b.Max
+	}
+
+	if ceil <= 0 {
+		return 0
+	}
+
+	return rand.N(ceil + 1)
+}
@@ -0,0 +1,159 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Status struct {
+	State    State
+	Failures int
+	OpenedAt time.Time
+}
+
+// Breaker opens after threshold consecutive failures and lets a single
+// probe through once cooldown has passed.
+type Breaker struct {
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	threshold int
+	cooldown  time.Duration
+	onChange  func(from, to State)
+}
+
+func New(threshold int, cooldown time.Duration, onChange func(from, to State)) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		onChange:  onChange,
+	}
+}
+
+// Allow reports whether a call may be made. Every allowed call must be
+// followed by Success, Failure or Abort.
+func (b *Breaker) Allow() error {
+	notify := func() {}
+	defer func() { notify() }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+
+		notify = b.setState(StateHalfOpen)
+		b.probing = true
+
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) Success() {
+	notify := func() {}
+	defer func() { notify() }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+
+	if b.state != StateClosed {
+		notify = b.setState(StateClosed)
+	}
+}
+
+func (b *Breaker) Failure() {
+	notify := func() {}
+	defer func() { notify() }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+
+		if b.state != StateOpen {
+			notify = b.setState(StateOpen)
+		}
+	}
+}
+
+// Abort releases an allowed call without recording its outcome.
+func (b *Breaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Status{
+		State:    b.state,
+		Failures: b.failures,
+		OpenedAt: b.openedAt,
+	}
+}
+
+// setState switches to the state and returns the onChange notification,
+// which the caller runs once b.mu is released.
+func (b *Breaker) setState(to State) func() {
+	from := b.state
+	b.state = to
+
+	return func() {
+		if b.onChange != nil {
+			b.onChange(from, to)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package translit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type Scheme string
+
+const (
+	SchemeNone Scheme = "none"
+	// SchemeICAO is ICAO Doc 9303, used in Russian foreign passports.
+	SchemeICAO Scheme = "icao"
+	// SchemeGOST is GOST 7.79-2000 system B without the apostrophes it
+	// uses for ъ, ь, ы and э, which the lookup APIs do not understand.
+	SchemeGOST Scheme = "gost"
+)
+
+var icao = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+	'і': "i", 'ї': "i", 'є': "ie", 'ґ': "g",
+}
+
+var gost = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "x", 'ц': "cz", 'ч': "ch", 'ш': "sh", 'щ': "shh",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+func ParseScheme(s string) (Scheme, error) {
+	switch scheme := Scheme(strings.ToLower(s)); scheme {
+	case SchemeNone, SchemeICAO, SchemeGOST:
+		return scheme, nil
+	case "":
+		return SchemeNone, nil
+	default:
+		return "", fmt.Errorf("unknown transliteration scheme %q", s)
+	}
+}
+
+func HasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transliterate converts Cyrillic letters of s to Latin, keeping the case
+// of each letter and leaving other characters as they are.
+func Transliterate(s string, scheme Scheme) string {
+	var table map[rune]string
+	switch scheme {
+	case SchemeICAO:
+		table = icao
+	case SchemeGOST:
+		table = gost
+	default:
+		return s
+	}
+
+	runes := []rune(s)
+
+	var b strings.Builder
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+
+		latin, ok := table[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+
+		// GOST writes ц as c before e, i, y and j.
+		if scheme == SchemeGOST && lower == 'ц' && i+1 < len(runes) {
+			switch unicode.ToLower(runes[i+1]) {
+			case 'е', 'и', 'ы', 'й', 'э', 'і', 'є':
+				latin = "c"
+			}
+		}
+
+		if latin == "" || lower == r {
+			b.WriteString(latin)
+			continue
+		}
+
+		// An upper-case letter inside an upper-case word stays fully
+		// upper-case, otherwise only its first Latin letter is.
+		if i+1 < len(runes) && unicode.IsUpper(runes[i+1]) || i > 0 && unicode.IsUpper(runes[i-1]) && (i+1 == len(runes) || !unicode.IsLetter(runes[i+1])) {
+			b.WriteString(strings.ToUpper(latin))
+			continue
+		}
+
+		b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+	}
+
+	return b.String()
+}
@@ -1,38 +1,99 @@
 package music
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/slavic"
 	"github.com/stepan41k/Effective-Mobile/internal/lib/api/logger/sl"
 	"github.com/stepan41k/Effective-Mobile/internal/service"
 	"github.com/stepan41k/Effective-Mobile/internal/storage"
 )
 
 type Profile interface {
-	TakeProfiles(ctx context.Context, person models.GetPerson) (persons []models.Person, err error)
+	TakeProfiles(ctx context.Context, person models.GetPerson) (page models.ProfilePage, err error)
+	TakeProfile(ctx context.Context, guid string) (person models.Person, err error)
 	RemoveProfile(ctx context.Context, person models.DeletePerson) (guid []byte, err error)
 	UpdateProfile(ctx context.Context, person models.UpdatedPerson) (guid []byte, err error)
 	NewProfile(ctx context.Context, person models.EnrichedPerson) (guid []byte, err error)
+	UpdateEnrichment(ctx context.Context, person models.EnrichedPerson) (err error)
+	RefreshEnrichment(ctx context.Context, person models.EnrichedPerson) (err error)
+	EnrichmentTarget(ctx context.Context, guid string) (person models.EnrichedPerson, err error)
+	EnrichmentTargets(ctx context.Context, filter models.GetPerson) (persons []models.EnrichedPerson, err error)
 }
 
+const (
+	GenderRulesOff      = "off"
+	GenderRulesFallback = "fallback"
+	GenderRulesOverride = "override"
+)
+
+const (
+	// PolicyStrict rejects the profile when any provider fails.
+	PolicyStrict = "strict"
+	// PolicyPartial stores the fields that succeeded and marks the rest as pending.
+	PolicyPartial = "partial"
+	// PolicyDeferred stores the raw name and enriches it later.
+	PolicyDeferred = "deferred"
+)
+
 type ProfileService struct {
-	profile Profile
-	log     *slog.Logger
+	profile     Profile
+	enricher    enrichment.Enricher
+	policy      string
+	confidence  models.ConfidencePolicy
+	genderRules string
+	country     string
+	log         *slog.Logger
 }
 
-func New(profile Profile, log *slog.Logger) *ProfileService {
+func New(profile Profile, enricher enrichment.Enricher, policy string, confidence models.ConfidencePolicy, genderRules string, defaultCountry string, log *slog.Logger) *ProfileService {
+	switch policy {
+	case PolicyStrict, PolicyPartial, PolicyDeferred:
+	default:
+		log.Warn("unknown enrichment policy, falling back to strict", slog.String("policy", policy))
+
+		policy = PolicyStrict
+	}
+
+	switch confidence.LowConfidenceGender {
+	case models.LowConfidenceUnknown, models.LowConfidenceOther:
+	default:
+		log.Warn("unknown low confidence gender, falling back to unknown", slog.String("low_confidence_gender", confidence.LowConfidenceGender))
+
+		confidence.LowConfidenceGender = models.LowConfidenceUnknown
+	}
+
+	switch genderRules {
+	case GenderRulesOff, GenderRulesFallback, GenderRulesOverride:
+	default:
+		log.Warn("unknown gender rules precedence, falling back to off", slog.String("gender_rules", genderRules))
+
+		genderRules = GenderRulesOff
+	}
+
 	return &ProfileService{
-		profile: profile,
-		log:     log,
+		profile:     profile,
+		enricher:    enricher,
+		policy:      policy,
+		confidence:  confidence,
+		genderRules: genderRules,
+		country:     strings.ToUpper(defaultCountry),
+		log:         log,
 	}
 }
 
-func (m *ProfileService) TakeProfiles(ctx context.Context, person models.GetPerson) ([]models.Person, error) {
+func (m *ProfileService) TakeProfiles(ctx context.Context, person models.GetPerson) (models.ProfilePage, error) {
 	const op = "service.music.GetProfiles"
 
 	log := m.log.With(
@@ -43,22 +104,44 @@ func (m *ProfileService) TakeProfiles(ctx context.Context, person models.GetPers
 
 	log.Info("getting profiles")
 
-	profiles, err := m.profile.TakeProfiles(ctx, person)
+	page, err := m.profile.TakeProfiles(ctx, person)
+	if err != nil {
+		log.Error("failed to get profiles", sl.Err(err))
+
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("got profiles")
+
+	return page, nil
+}
+
+func (m *ProfileService) TakeProfile(ctx context.Context, guid string) (models.Person, error) {
+	const op = "service.profile.TakeProfile"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("guid", guid),
+	)
+
+	log.Info("getting profile")
+
+	profile, err := m.profile.TakeProfile(ctx, guid)
 	if err != nil {
-		if errors.Is(err, storage.ErrProfilesNotFound) {
-			log.Warn("profiles not found")
+		if errors.Is(err, storage.ErrProfileNotFound) {
+			log.Warn("profile not found")
 
-			return nil, fmt.Errorf("%s: %w", op, service.ErrProfilesNotFound)
+			return models.Person{}, fmt.Errorf("%s: %w", op, service.ErrProfileNotFound)
 		}
 
-		log.Error("failed to get profiles", sl.Err(err))
+		log.Error("failed to get profile", sl.Err(err))
 
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return models.Person{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Info("got profiles")
+	log.Info("got profile")
 
-	return profiles, nil
+	return profile, nil
 }
 
 func (m *ProfileService) RemoveProfile(ctx context.Context, person models.DeletePerson) ([]byte, error) {
@@ -98,6 +181,8 @@ func (m *ProfileService) UpdateProfile(ctx context.Context, person models.Update
 
 	log.Info("updating profile")
 
+	person.Nationalize = strings.ToUpper(person.Nationalize)
+
 	id, err := m.profile.UpdateProfile(ctx, person)
 	if err != nil {
 		if errors.Is(err, storage.ErrProfileNotFound) {
@@ -121,7 +206,7 @@ func (m *ProfileService) UpdateProfile(ctx context.Context, person models.Update
 	return id, nil
 }
 
-func (m *ProfileService) NewProfile(ctx context.Context, person models.EnrichedPerson) ([]byte, error) {
+func (m *ProfileService) NewProfile(ctx context.Context, person models.NewPerson) ([]byte, error) {
 	const op = "service.music.NewProfile"
 
 	log := m.log.With(
@@ -132,22 +217,416 @@ func (m *ProfileService) NewProfile(ctx context.Context, person models.EnrichedP
 
 	log.Info("creating new profile")
 
+	profile := models.EnrichedPerson{
+		Name:             person.Name,
+		Surname:          person.Surname,
+		Patronymic:       person.Patronymic,
+		CountryHint:      strings.ToUpper(person.CountryHint),
+		EnrichmentStatus: models.EnrichmentComplete,
+	}
+
+	if m.policy == PolicyDeferred {
+		profile.EnrichmentStatus = models.EnrichmentPending
+		profile.PendingFields = allFields()
+	} else {
+		res, err := m.enricher.Enrich(ctx, m.query(profile))
+
+		var enrichErr *enrichment.Error
+		if err != nil && (m.policy == PolicyStrict || !errors.As(err, &enrichErr)) {
+			log.Error("failed to enrich profile", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w: %w", op, service.ErrEnrichmentFailed, err)
+		}
+
+		if err != nil {
+			log.Warn("profile partially enriched", sl.Err(err))
+
+			profile.PendingFields = enrichErr.FailedProviders()
+			profile.EnrichmentStatus = enrichmentStatus(profile.PendingFields)
+		}
+
+		applyResult(&profile, res)
+		m.applyConfidence(&profile)
+	}
+
+	m.applyGenderRules(&profile)
+
 	guid, err := uuid.NewRandom()
 	if err != nil {
 		log.Error("failed to generate guid")
 
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	person.GUID = guid.String()
+	profile.GUID = guid.String()
 
-	id, err := m.profile.NewProfile(ctx, person)
+	id, err := m.profile.NewProfile(ctx, profile)
 	if err != nil {
 		log.Error("failed to add profile", sl.Err(err))
 
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Info("profile added")
+	log.Info("profile added", slog.String("enrichment_status", profile.EnrichmentStatus))
 
 	return id, nil
 }
+
+func (m *ProfileService) EnrichProfile(ctx context.Context, person models.EnrichedPerson) ([]string, error) {
+	const op = "service.profile.EnrichProfile"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("guid", person.GUID),
+	)
+
+	log.Info("enriching profile")
+
+	enrichErr, err := m.enrich(ctx, &person, false)
+	if err != nil {
+		log.Error("failed to enrich profile", sl.Err(err))
+
+		return person.PendingFields, fmt.Errorf("%s: %w: %w", op, service.ErrEnrichmentFailed, err)
+	}
+
+	if err := m.profile.UpdateEnrichment(ctx, person); err != nil {
+		if errors.Is(err, storage.ErrProfileNotFound) {
+			log.Warn("profile not found")
+
+			return nil, fmt.Errorf("%s: %w", op, service.ErrProfileNotFound)
+		}
+
+		log.Error("failed to save enrichment", sl.Err(err))
+
+		return person.PendingFields, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(person.PendingFields) > 0 {
+		log.Warn("profile partially enriched", sl.Err(enrichErr))
+
+		return person.PendingFields, fmt.Errorf("%s: %w: %w", op, service.ErrEnrichmentFailed, enrichErr)
+	}
+
+	log.Info("profile enriched")
+
+	return nil, nil
+}
+
+// ReenrichProfile looks the profile up again and overwrites every enriched
+// field that was not set manually, bypassing the enrichment cache. Fields
+// whose provider fails keep their previous values.
+func (m *ProfileService) ReenrichProfile(ctx context.Context, guid string) (models.ReenrichResult, error) {
+	const op = "service.profile.ReenrichProfile"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("guid", guid),
+	)
+
+	log.Info("re-enriching profile")
+
+	person, err := m.profile.EnrichmentTarget(ctx, guid)
+	if err != nil {
+		if errors.Is(err, storage.ErrProfileNotFound) {
+			log.Warn("profile not found")
+
+			return models.ReenrichResult{}, fmt.Errorf("%s: %w", op, service.ErrProfileNotFound)
+		}
+
+		log.Error("failed to get profile", sl.Err(err))
+
+		return models.ReenrichResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err := m.reenrich(ctx, person)
+	if err != nil {
+		if errors.Is(err, service.ErrProfileNotFound) {
+			log.Warn("profile not found")
+		} else {
+			log.Error("failed to re-enrich profile", sl.Err(err))
+		}
+
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("profile re-enriched", slog.Any("refreshed", result.Refreshed), slog.Any("failed", result.Failed))
+
+	return result, nil
+}
+
+// ReenrichProfiles re-enriches every profile matching the filter, running up
+// to workers lookups at a time. Failures are counted, not returned.
+func (m *ProfileService) ReenrichProfiles(ctx context.Context, filter models.GetPerson, workers int) (models.ReenrichSummary, error) {
+	const op = "service.profile.ReenrichProfiles"
+
+	log := m.log.With(slog.String("op", op))
+
+	persons, err := m.profile.EnrichmentTargets(ctx, filter)
+	if err != nil {
+		log.Error("failed to get profiles", sl.Err(err))
+
+		return models.ReenrichSummary{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("re-enriching profiles", slog.Int("matched", len(persons)))
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		summary = models.ReenrichSummary{Matched: len(persons)}
+		sem     = make(chan struct{}, max(workers, 1))
+	)
+
+	for _, person := range persons {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result, err := m.reenrich(ctx, person)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err != nil:
+				log.Warn("failed to re-enrich profile", slog.String("guid", person.GUID), sl.Err(err))
+
+				summary.Failed++
+			case len(result.Refreshed) == 0 && len(result.Failed) == 0:
+				summary.Skipped++
+			default:
+				summary.Refreshed++
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	log.Info("profiles re-enriched",
+		slog.Int("refreshed", summary.Refreshed),
+		slog.Int("skipped", summary.Skipped),
+		slog.Int("failed", summary.Failed),
+	)
+
+	return summary, ctx.Err()
+}
+
+func (m *ProfileService) reenrich(ctx context.Context, person models.EnrichedPerson) (models.ReenrichResult, error) {
+	manual := person.Provenance.Manual()
+	result := models.ReenrichResult{GUID: person.GUID, Skipped: manual}
+
+	if len(manual) == len(allFields()) {
+		return result, nil
+	}
+
+	enrichErr, err := m.enrich(ctx, &person, true)
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", service.ErrEnrichmentFailed, err)
+	}
+
+	for _, field := range allFields() {
+		switch {
+		case slices.Contains(manual, field):
+		case slices.Contains(person.PendingFields, field):
+			result.Failed = append(result.Failed, field)
+		default:
+			result.Refreshed = append(result.Refreshed, field)
+		}
+	}
+
+	if len(result.Refreshed) == 0 {
+		return result, fmt.Errorf("%w: %w", service.ErrEnrichmentFailed, enrichErr)
+	}
+
+	if err := m.profile.RefreshEnrichment(ctx, person); err != nil {
+		if errors.Is(err, storage.ErrProfileNotFound) {
+			return result, service.ErrProfileNotFound
+		}
+
+		return result, err
+	}
+
+	return result, nil
+}
+
+// enrich looks the profile up and applies the result, the confidence
+// thresholds and the gender rules. Fields whose provider failed are left
+// in PendingFields and reported by the returned *enrichment.Error. With
+// refresh set cached answers are skipped.
+func (m *ProfileService) enrich(ctx context.Context, person *models.EnrichedPerson, refresh bool) (*enrichment.Error, error) {
+	q := m.query(*person)
+	q.Refresh = refresh
+
+	res, err := m.enricher.Enrich(ctx, q)
+
+	var enrichErr *enrichment.Error
+	if err != nil && !errors.As(err, &enrichErr) {
+		return nil, err
+	}
+
+	var pending []string
+	if enrichErr != nil {
+		pending = enrichErr.FailedProviders()
+	}
+
+	applyResult(person, res)
+	person.PendingFields = pending
+	person.EnrichmentStatus = enrichmentStatus(pending)
+	m.applyConfidence(person)
+	m.applyGenderRules(person)
+
+	return enrichErr, nil
+}
+
+// query builds the enrichment query for a profile. Profiles without a
+// country hint of their own use the service-wide default.
+func (m *ProfileService) query(profile models.EnrichedPerson) enrichment.Query {
+	country := profile.CountryHint
+	if country == "" {
+		country = m.country
+	}
+
+	return enrichment.Query{Name: profile.Name, CountryID: country}
+}
+
+func applyResult(profile *models.EnrichedPerson, res enrichment.Result) {
+	profile.QueryName = res.Query
+	if profile.QueryName == "" {
+		profile.QueryName = profile.Name
+	}
+
+	profile.Age = res.Age.Age
+	profile.AgeCount = res.Age.Count
+	profile.Gender = res.Gender.Gender
+	profile.GenderProbability = res.Gender.Probability
+	profile.GenderCount = res.Gender.Count
+	profile.GenderSource = ""
+	if profile.Gender != "" {
+		profile.GenderSource = models.GenderSourceProvider
+	}
+
+	profile.Provenance = nil
+	for field, p := range res.Provenance {
+		setProvenance(profile, field, p)
+	}
+
+	profile.Countries = slices.Clone(res.Nationalize.Country)
+	slices.SortStableFunc(profile.Countries, func(a, b models.Country) int {
+		return cmp.Compare(b.Probability, a.Probability)
+	})
+
+	if len(profile.Countries) > 0 {
+		profile.Nationalize = profile.Countries[0].CountryID
+	}
+}
+
+// applyConfidence replaces inferred values that do not meet the configured
+// thresholds and records the policy on the profile. The raw values are kept
+// in InferredAge, InferredGender and Countries so the thresholds can be
+// re-applied later.
+func (m *ProfileService) applyConfidence(profile *models.EnrichedPerson) {
+	policy := m.confidence
+	profile.ConfidencePolicy = &policy
+	profile.InferredAge = profile.Age
+	profile.InferredGender = profile.Gender
+	profile.LowConfidence = nil
+
+	accepted := func(field string, ok bool) bool {
+		if ok || slices.Contains(profile.PendingFields, field) {
+			return true
+		}
+
+		profile.LowConfidence = append(profile.LowConfidence, field)
+
+		return false
+	}
+
+	if profile.Age != 0 && !accepted(models.FieldAge, profile.AgeCount >= policy.MinAgeCount) {
+		profile.Age = 0
+	}
+
+	if profile.Gender != "" && !accepted(models.FieldGender, profile.GenderProbability >= policy.MinGenderProbability) {
+		profile.Gender = ""
+		if policy.LowConfidenceGender == models.LowConfidenceOther {
+			profile.Gender = models.LowConfidenceOther
+		}
+	}
+
+	var top float32
+	if len(profile.Countries) > 0 {
+		top = profile.Countries[0].Probability
+	}
+
+	if profile.Nationalize != "" && !accepted(models.FieldNationalize, top >= policy.MinCountryProbability) {
+		profile.Nationalize = ""
+	}
+}
+
+// applyGenderRules infers gender from the patronymic or surname, with the
+// country hint and the top nationality telling Slavic surnames apart. With
+// GenderRulesOverride a matching rule always wins, with GenderRulesFallback
+// it is only used when the provider gave no answer, a low-confidence one,
+// or failed.
+func (m *ProfileService) applyGenderRules(profile *models.EnrichedPerson) {
+	if m.genderRules == GenderRulesOff {
+		return
+	}
+
+	inference, ok := slavic.InferGender(profile.Surname, profile.Patronymic, m.query(*profile).CountryID, profile.Nationalize)
+	if !ok {
+		return
+	}
+
+	unresolved := profile.Gender == "" ||
+		slices.Contains(profile.LowConfidence, models.FieldGender) ||
+		slices.Contains(profile.PendingFields, models.FieldGender)
+
+	if m.genderRules == GenderRulesFallback && !unresolved {
+		return
+	}
+
+	profile.Gender = inference.Gender
+	profile.GenderSource = inference.Source
+	setProvenance(profile, models.FieldGender, models.FieldProvenance{Kind: models.ProvenanceInferred, Source: inference.Source})
+	profile.GenderProbability = 0
+	profile.GenderCount = 0
+
+	profile.LowConfidence = slices.DeleteFunc(profile.LowConfidence, func(f string) bool { return f == models.FieldGender })
+
+	if slices.Contains(profile.PendingFields, models.FieldGender) {
+		profile.PendingFields = slices.DeleteFunc(profile.PendingFields, func(f string) bool { return f == models.FieldGender })
+		profile.EnrichmentStatus = enrichmentStatus(profile.PendingFields)
+	}
+}
+
+func setProvenance(profile *models.EnrichedPerson, field string, p models.FieldProvenance) {
+	if profile.Provenance == nil {
+		profile.Provenance = make(models.Provenance)
+	}
+
+	p.UpdatedAt = time.Now().UTC()
+	profile.Provenance[field] = p
+}
+
+func enrichmentStatus(pending []string) string {
+	switch len(pending) {
+	case 0:
+		return models.EnrichmentComplete
+	case len(allFields()):
+		return models.EnrichmentPending
+	default:
+		return models.EnrichmentPartial
+	}
+}
+
+func allFields() []string {
+	return []string{models.FieldAge, models.FieldGender, models.FieldNationalize}
+}
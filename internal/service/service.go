@@ -4,6 +4,6 @@ import "errors"
 
 var (
 	ErrNoChanges        = errors.New("no changes")
-	ErrProfilesNotFound = errors.New("profiles not found")
 	ErrProfileNotFound  = errors.New("profile not found")
+	ErrEnrichmentFailed = errors.New("failed to enrich profile")
 )
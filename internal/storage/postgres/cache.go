@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Effective-Mobile/internal/storage"
+)
+
+func (s *PStorage) CachedEnrichment(ctx context.Context, key string) (payload []byte, expiresAt time.Time, err error) {
+	const op = "storage.postgres.cache.CachedEnrichment"
+
+	err = s.pool.QueryRow(ctx, `
+		SELECT result, expires_at FROM enrichment_cache
+		WHERE key = $1 AND expires_at > now();
+	`, key).Scan(&payload, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, time.Time{}, fmt.Errorf("%s: %w", op, storage.ErrCacheMiss)
+		}
+
+		return nil, time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return payload, expiresAt, nil
+}
+
+func (s *PStorage) SaveCachedEnrichment(ctx context.Context, key string, payload []byte, expiresAt time.Time) error {
+	const op = "storage.postgres.cache.SaveCachedEnrichment"
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO enrichment_cache (key, result, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET result = EXCLUDED.result, expires_at = EXCLUDED.expires_at;
+	`, key, payload, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PStorage) DeleteExpiredEnrichment(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.cache.DeleteExpiredEnrichment"
+
+	cTag, err := s.pool.Exec(ctx, `
+		DELETE FROM enrichment_cache
+		WHERE expires_at <= now();
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cTag.RowsAffected(), nil
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+)
+
+// countriesColumn aggregates the ranked country distribution of a profile.
+const countriesColumn = `COALESCE((
+	SELECT json_agg(json_build_object('country_id', c.country_id, 'probability', c.probability) ORDER BY c.rank)
+	FROM profile_countries c
+	WHERE c.profile_guid = profiles.guid
+), '[]')`
+
+func saveCountries(ctx context.Context, tx pgx.Tx, guid []byte, countries []models.Country) error {
+	const op = "storage.postgres.countries.saveCountries"
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM profile_countries
+		WHERE profile_guid = $1;
+	`, guid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(countries) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(countries))
+	probabilities := make([]float32, 0, len(countries))
+	for _, c := range countries {
+		ids = append(ids, c.CountryID)
+		probabilities = append(probabilities, c.Probability)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO profile_countries (profile_guid, rank, country_id, probability)
+		SELECT $1, t.rank - 1, t.country_id, t.probability
+		FROM unnest($2::text[], $3::real[]) WITH ORDINALITY AS t(country_id, probability, rank);
+	`, guid, ids, probabilities)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,375 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+)
+
+// filterColumns maps filter fields to SQL expressions over profiles.
+var filterColumns = map[filter.Field]string{
+	filter.FieldName:               `name`,
+	filter.FieldSurname:            `surname`,
+	filter.FieldPatronymic:         `patronymic`,
+	filter.FieldAge:                `age`,
+	filter.FieldGender:             `gender::text`,
+	filter.FieldNationalize:        `nationalize`,
+	filter.FieldGenderProbability:  `gender_probability`,
+	filter.FieldCountryProbability: `(SELECT c.probability FROM profile_countries c WHERE c.profile_guid = profiles.guid AND c.rank = 0)`,
+}
+
+// columnTypes are the SQL types of the REAL columns. A float64 parameter
+// makes Postgres compare them as float8, where a stored 0.9 is 0.8999999762,
+// so their placeholders are cast to the column type instead.
+var columnTypes = map[filter.Field]string{
+	filter.FieldGenderProbability:  `real`,
+	filter.FieldCountryProbability: `real`,
+}
+
+var comparisons = map[filter.Op]string{
+	filter.OpEq:  `=`,
+	filter.OpNe:  `IS DISTINCT FROM`,
+	filter.OpGt:  `>`,
+	filter.OpGte: `>=`,
+	filter.OpLt:  `<`,
+	filter.OpLte: `<=`,
+}
+
+// Where compiles the filter tree to a parameterized SQL condition over
+// profiles with placeholders numbered from $1, and returns the values for
+// them. String comparisons are case-sensitive, prefix and contains included.
+func Where(expr filter.Expr) (string, []any, error) {
+	var values []any
+
+	where, err := compileFilter(expr, &values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return where, values, nil
+}
+
+// compileFilter turns the filter tree into a parameterized SQL condition,
+// appending the values to args. Placeholders continue from len(*args).
+func compileFilter(expr filter.Expr, args *[]any) (string, error) {
+	switch e := expr.(type) {
+	case filter.And:
+		parts := make([]string, 0, len(e))
+		for _, sub := range e {
+			part, err := compileFilter(sub, args)
+			if err != nil {
+				return "", err
+			}
+
+			parts = append(parts, part)
+		}
+
+		if len(parts) == 0 {
+			return `TRUE`, nil
+		}
+
+		return `(` + strings.Join(parts, ` AND `) + `)`, nil
+	case filter.Condition:
+		return compileCondition(e, args)
+	}
+
+	return "", fmt.Errorf("%w: unexpected node %T", filter.ErrInvalid, expr)
+}
+
+func compileCondition(c filter.Condition, args *[]any) (string, error) {
+	column, ok := filterColumns[c.Field]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown field %q", filter.ErrInvalid, c.Field)
+	}
+
+	if len(c.Values) == 0 {
+		return "", fmt.Errorf("%w: %s[%s] has no value", filter.ErrInvalid, c.Field, c.Op)
+	}
+
+	typ, cast := columnTypes[c.Field]
+
+	placeholder := func(v any) string {
+		*args = append(*args, v)
+
+		return fmt.Sprintf(`$%d`, len(*args))
+	}
+
+	switch c.Op {
+	case filter.OpIn:
+		if cast {
+			return fmt.Sprintf(`%s = ANY(%s::%s[])`, column, placeholder(arrayOf(c.Values)), typ), nil
+		}
+
+		return fmt.Sprintf(`%s = ANY(%s)`, column, placeholder(arrayOf(c.Values))), nil
+	case filter.OpPrefix:
+		return fmt.Sprintf(`%s LIKE %s`, column, placeholder(escapeLike(fmt.Sprint(c.Values[0]))+`%`)), nil
+	case filter.OpContains:
+		return fmt.Sprintf(`%s LIKE %s`, column, placeholder(`%`+escapeLike(fmt.Sprint(c.Values[0]))+`%`)), nil
+	}
+
+	cmp, ok := comparisons[c.Op]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown operator %q", filter.ErrInvalid, c.Op)
+	}
+
+	if cast {
+		return fmt.Sprintf(`%s %s %s::%s`, column, cmp, placeholder(c.Values[0]), typ), nil
+	}
+
+	return fmt.Sprintf(`%s %s %s`, column, cmp, placeholder(c.Values[0])), nil
+}
+
+// sortColumns maps sortable fields to non-null expressions over profiles,
+// so that keyset conditions compare the same values the rows are ordered by.
+var sortColumns = map[filter.Field]string{
+	filter.FieldName:              `name`,
+	filter.FieldSurname:           `surname`,
+	filter.FieldPatronymic:        `COALESCE(patronymic, '')`,
+	filter.FieldAge:               `COALESCE(age, 0)`,
+	filter.FieldGender:            `COALESCE(gender::text, '')`,
+	filter.FieldNationalize:       `COALESCE(nationalize, '')`,
+	filter.FieldGenderProbability: `COALESCE(gender_probability, 0)`,
+	filter.FieldCreatedAt:         `created_at`,
+	filter.FieldUpdatedAt:         `updated_at`,
+}
+
+// sortKeys returns the ordering expressions with their directions. The guid
+// always breaks ties, in the direction of the last key so that an index on
+// (column, guid) can serve the whole ordering.
+func sortKeys(sort filter.Sort) ([]string, []bool, error) {
+	columns := make([]string, 0, len(sort)+1)
+	desc := make([]bool, 0, len(sort)+1)
+
+	last := false
+	for _, key := range sort {
+		column, ok := sortColumns[key.Field]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: cannot sort by %q", filter.ErrInvalid, key.Field)
+		}
+
+		last = key.Desc
+		columns = append(columns, column)
+		desc = append(desc, key.Desc)
+	}
+
+	return append(columns, `guid`), append(desc, last), nil
+}
+
+// orderBy builds the ORDER BY clause, with every direction flipped when
+// reverse is set.
+func orderBy(sort filter.Sort, reverse bool) (string, error) {
+	columns, desc, err := sortKeys(sort)
+	if err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(columns))
+	for i, column := range columns {
+		if desc[i] != reverse {
+			column += ` DESC`
+		}
+
+		parts = append(parts, column)
+	}
+
+	return ` ORDER BY ` + strings.Join(parts, `, `), nil
+}
+
+// keyset builds the condition selecting the rows that follow the cursor
+// row in the given ordering, or precede it when the cursor points back.
+func keyset(sort filter.Sort, cursor *filter.Cursor, args *[]any) (string, error) {
+	if cursor.Sort != sort.String() || len(cursor.Values) != len(sort) {
+		return "", fmt.Errorf("%w: cursor does not match sort", filter.ErrInvalid)
+	}
+
+	columns, desc, err := sortKeys(sort)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]any, 0, len(columns))
+	for i, key := range sort {
+		v, err := cursorValue(key.Field, cursor.Values[i])
+		if err != nil {
+			return "", err
+		}
+
+		values = append(values, v)
+	}
+
+	values = append(values, []byte(cursor.GUID))
+
+	// The keys are cast to the type of their column, or a REAL key would
+	// never equal the cursor's own value and ties would be skipped.
+	placeholders := make([]string, 0, len(values))
+	for i, v := range values {
+		*args = append(*args, v)
+		placeholder := fmt.Sprintf(`$%d`, len(*args))
+
+		if i < len(sort) {
+			if typ, ok := columnTypes[sort[i].Field]; ok {
+				placeholder += `::` + typ
+			}
+		}
+
+		placeholders = append(placeholders, placeholder)
+	}
+
+	// (a > $1) OR (a = $1 AND b < $2) OR ..., as the directions may differ.
+	ors := make([]string, 0, len(columns))
+	for i, column := range columns {
+		cmp := `>`
+		if desc[i] != cursor.Before {
+			cmp = `<`
+		}
+
+		ands := make([]string, 0, i+1)
+		for j := range i {
+			ands = append(ands, fmt.Sprintf(`%s = %s`, columns[j], placeholders[j]))
+		}
+
+		ands = append(ands, fmt.Sprintf(`%s %s %s`, column, cmp, placeholders[i]))
+		ors = append(ors, `(`+strings.Join(ands, ` AND `)+`)`)
+	}
+
+	return `(` + strings.Join(ors, ` OR `) + `)`, nil
+}
+
+// cursorValue converts a decoded cursor value back to the type of its
+// sort column.
+func cursorValue(field filter.Field, v any) (any, error) {
+	switch field {
+	case filter.FieldCreatedAt, filter.FieldUpdatedAt:
+		s, _ := v.(string)
+
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", filter.ErrInvalid)
+		}
+
+		return t, nil
+	case filter.FieldAge, filter.FieldGenderProbability:
+		if _, ok := v.(float64); !ok {
+			return nil, fmt.Errorf("%w: malformed cursor", filter.ErrInvalid)
+		}
+
+		return v, nil
+	}
+
+	if _, ok := v.(string); !ok {
+		return nil, fmt.Errorf("%w: malformed cursor", filter.ErrInvalid)
+	}
+
+	return v, nil
+}
+
+// cursorAt returns the token of the cursor pointing at person in the given
+// ordering.
+func cursorAt(sort filter.Sort, person models.Person, before bool) string {
+	values := make([]any, 0, len(sort))
+	for _, key := range sort {
+		values = append(values, sortValue(key.Field, person))
+	}
+
+	cursor := filter.Cursor{Sort: sort.String(), Values: values, GUID: person.GUID, Before: before}
+
+	return cursor.String()
+}
+
+// sortValue is the value of the sort expression of field for person.
+func sortValue(field filter.Field, person models.Person) any {
+	switch field {
+	case filter.FieldName:
+		return person.Name
+	case filter.FieldSurname:
+		return person.Surname
+	case filter.FieldPatronymic:
+		return person.Patronymic
+	case filter.FieldAge:
+		return person.Age
+	case filter.FieldGender:
+		return person.Gender
+	case filter.FieldNationalize:
+		return person.Nationalize
+	case filter.FieldGenderProbability:
+		return person.GenderProbability
+	case filter.FieldCreatedAt:
+		return person.CreatedAt
+	case filter.FieldUpdatedAt:
+		return person.UpdatedAt
+	}
+
+	return nil
+}
+
+// personFilter translates the body filters of POST /profile/take into the
+// filter tree and appends the query-string conditions.
+func personFilter(person models.GetPerson) filter.And {
+	var and filter.And
+
+	eq := func(field filter.Field, value string) {
+		if value != "" {
+			and = append(and, filter.Condition{Field: field, Op: filter.OpEq, Values: []any{value}})
+		}
+	}
+
+	eq(filter.FieldName, person.Name)
+	eq(filter.FieldSurname, person.Surname)
+	eq(filter.FieldPatronymic, person.Patronymic)
+	eq(filter.FieldGender, person.Gender)
+	eq(filter.FieldNationalize, person.Nationalize)
+
+	if person.Age != 0 {
+		op := filter.OpLt
+		if person.Greater {
+			op = filter.OpGt
+		}
+
+		and = append(and, filter.Condition{Field: filter.FieldAge, Op: op, Values: []any{person.Age}})
+	}
+
+	if person.MinGenderProbability != 0 {
+		and = append(and, filter.Condition{Field: filter.FieldGenderProbability, Op: filter.OpGte, Values: []any{float64(person.MinGenderProbability)}})
+	}
+
+	if person.MinCountryProbability != 0 {
+		and = append(and, filter.Condition{Field: filter.FieldCountryProbability, Op: filter.OpGte, Values: []any{float64(person.MinCountryProbability)}})
+	}
+
+	return append(and, person.Filter...)
+}
+
+// arrayOf converts the values of an IN condition to a typed slice, so pgx
+// can encode it as an array.
+func arrayOf(values []any) any {
+	switch values[0].(type) {
+	case int:
+		ints := make([]int, 0, len(values))
+		for _, v := range values {
+			ints = append(ints, v.(int))
+		}
+
+		return ints
+	case float64:
+		floats := make([]float64, 0, len(values))
+		for _, v := range values {
+			floats = append(floats, v.(float64))
+		}
+
+		return floats
+	}
+
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, fmt.Sprint(v))
+	}
+
+	return strs
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
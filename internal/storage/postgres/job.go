@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/storage"
+)
+
+func (s *PStorage) ClaimEnrichmentJobs(ctx context.Context, limit int, lease time.Duration) ([]models.EnrichmentJob, error) {
+	const op = "storage.postgres.job.ClaimEnrichmentJobs"
+
+	// Claimed jobs are hidden from other workers for the lease duration, so
+	// a crashed worker does not lose them.
+	rows, err := s.pool.Query(ctx, `
+		WITH claimed AS (
+			SELECT id FROM enrichment_jobs
+			WHERE run_at <= now() AND NOT failed
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE enrichment_jobs j
+		SET run_at = now() + make_interval(secs => $2)
+		FROM claimed, profiles p
+		WHERE j.id = claimed.id AND p.guid = j.profile_guid
+		RETURNING j.id, j.attempts, p.guid, p.name, p.surname, COALESCE(p.patronymic, ''), COALESCE(p.country_hint, ''), p.enrichment_status::text, p.pending_fields;
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	var jobs []models.EnrichmentJob
+	for rows.Next() {
+		var (
+			job  models.EnrichmentJob
+			guid []byte
+		)
+
+		err = rows.Scan(&job.ID, &job.Attempts, &guid, &job.Person.Name, &job.Person.Surname, &job.Person.Patronymic, &job.Person.CountryHint, &job.Person.EnrichmentStatus, &job.Person.PendingFields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		job.Person.GUID = string(guid)
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return jobs, nil
+}
+
+func (s *PStorage) DeleteEnrichmentJob(ctx context.Context, id int64) error {
+	const op = "storage.postgres.job.DeleteEnrichmentJob"
+
+	_, err := s.pool.Exec(ctx, `
+		DELETE FROM enrichment_jobs
+		WHERE id = $1;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RescheduleEnrichmentJob records a failed attempt. A job that failed
+// permanently also marks its profile as failed, so it does not stay
+// pending forever.
+func (s *PStorage) RescheduleEnrichmentJob(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr string, failed bool) (err error) {
+	const op = "storage.postgres.job.RescheduleEnrichmentJob"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		if commitErr := tx.Commit(ctx); commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	cTag, err := tx.Exec(ctx, `
+		UPDATE enrichment_jobs
+		SET attempts = $2, run_at = $3, last_error = $4, failed = $5
+		WHERE id = $1;
+	`, job.ID, job.Attempts, runAt, lastErr, failed)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cTag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrJobNotFound)
+	}
+
+	if !failed {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE profiles
+		SET enrichment_status = $2, updated_at = now()
+		WHERE guid = $1 AND enrichment_status <> $3;
+	`, []byte(job.Person.GUID), models.EnrichmentFailed, models.EnrichmentComplete)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -4,20 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/jackc/pgx"
+	pgxv4 "github.com/jackc/pgx/v4"
 	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
 	"github.com/stepan41k/Effective-Mobile/internal/storage"
 )
 
 
-func (s *PStorage) TakeProfiles(ctx context.Context, person models.GetPerson) ([]models.Person, error) {
+func (s *PStorage) TakeProfiles(ctx context.Context, person models.GetPerson) (models.ProfilePage, error) {
 	const op = "storage.postgres.profile.GetProfiles"
 
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	defer func() {
@@ -32,66 +34,53 @@ func (s *PStorage) TakeProfiles(ctx context.Context, person models.GetPerson) ([
 		}
 	}()
 	
-	arguments, values, ind := []string{}, []any{}, 1
-	query := `SELECT name, surname, patronymic, age, gender, nationalize FROM profiles`
+	query := `SELECT ` + profileColumns + ` FROM profiles`
 
-	if person.Name != "" || person.Surname != "" || person.Patronymic != "" || person.Gender != "" || person.Nationalize != "" || person.Age != 0 {
-		query += ` WHERE `
-		if person.Name != "" {
-			arguments = append(arguments, fmt.Sprintf(`name LIKE $%d`, ind))
-			values = append(values, person.Name)
-			ind++
-		}
-			
-		if person.Surname != "" {
-			arguments = append(arguments, fmt.Sprintf(`surname LIKE $%d`, ind))
-			values = append(values, person.Surname)
-			ind++
-		}
-			
-		if person.Patronymic != "" {
-			arguments = append(arguments, fmt.Sprintf(`patronymic LIKE $%d`, ind))
-			values = append(values, person.Patronymic)
-			ind++
-		}
-			
-		if person.Gender != "" {
-			arguments = append(arguments, fmt.Sprintf(`gender LIKE $%d`, ind))
-			values = append(values, person.Gender)
-			ind++
-		}
-			
-		if person.Nationalize != "" {
-			arguments = append(arguments, fmt.Sprintf(`nationalize LIKE $%d`, ind))
-			values = append(values, person.Nationalize)
-			ind++
+	where, values, err := profileFilter(person)
+	if err != nil {
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	total, estimated, err := countProfiles(ctx, tx, where, values)
+	if err != nil {
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cursor := person.Cursor
+	backward := cursor != nil && cursor.Before
+
+	if cursor != nil {
+		cond, err := keyset(person.Sort, cursor, &values)
+		if err != nil {
+			return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
 		}
-			
-		if person.Age != 0 {
-			if person.Greater {
-				arguments = append(arguments, fmt.Sprintf(`age > $%d`, ind))
-			} else {
-				arguments = append(arguments, fmt.Sprintf(`age < $%d`, ind))
-			}
-			values = append(values, person.Age)
-			ind++
+
+		if where == "" {
+			where = ` WHERE ` + cond
+		} else {
+			where += ` AND ` + cond
 		}
-	
-		query += strings.Join(arguments, " AND ")
 	}
 
-	query += fmt.Sprintf(` LIMIT $%d OFFSET $%d;`, ind, ind+1)
-	values = append(values, person.PageSize, (person.Page-1)*person.PageSize)
+	order, err := orderBy(person.Sort, backward)
+	if err != nil {
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
+	}
 
+	query += where + order
 
-	rows, err := tx.Query(ctx, query, values...)
+	// One row past the page tells whether there is more in that direction.
+	values = append(values, person.PageSize+1)
+	query += fmt.Sprintf(` LIMIT $%d`, len(values))
 
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("%s: %w", op, storage.ErrProfilesNotFound)
-		}
+	if cursor == nil {
+		values = append(values, (person.Page-1)*person.PageSize)
+		query += fmt.Sprintf(` OFFSET $%d`, len(values))
+	}
 
-		return nil, fmt.Errorf("%s: %w", op, err)
+	rows, err := tx.Query(ctx, query, values...)
+	if err != nil {
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	defer rows.Close()
@@ -99,14 +88,117 @@ func (s *PStorage) TakeProfiles(ctx context.Context, person models.GetPerson) ([
 	var persons []models.Person
 	for rows.Next() {
 		var item models.Person
-		err = rows.Scan(&item.Name, &item.Surname, &item.Patronymic, &item.Age, &item.Gender, &item.Nationalize)
+		err = scanPerson(rows, &item)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+			return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
 		}
 		persons = append(persons, item)
 	}
-	
-	return persons, err
+
+	if err = rows.Err(); err != nil {
+		return models.ProfilePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	more := len(persons) > person.PageSize
+	if more {
+		persons = persons[:person.PageSize]
+	}
+
+	if backward {
+		slices.Reverse(persons)
+	}
+
+	page := models.ProfilePage{
+		Profiles:       persons,
+		Total:          total,
+		TotalEstimated: estimated,
+	}
+
+	if cursor == nil {
+		page.Page = person.Page
+	}
+
+	if len(persons) == 0 {
+		return page, err
+	}
+
+	hasNext, hasPrev := more, cursor != nil || person.Page > 1
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+
+	page.HasMore = hasNext
+
+	if hasNext {
+		page.NextCursor = cursorAt(person.Sort, persons[len(persons)-1], false)
+	}
+
+	if hasPrev {
+		page.PrevCursor = cursorAt(person.Sort, persons[0], true)
+	}
+
+	return page, err
+}
+
+// estimateThreshold is the table size from which an unfiltered listing
+// reports the planner's row estimate instead of counting.
+const estimateThreshold = 100_000
+
+// countProfiles counts the profiles matching where. Without a filter, a
+// table past estimateThreshold rows is not scanned; the pg_class estimate
+// is returned and reported as such.
+func countProfiles(ctx context.Context, tx pgxv4.Tx, where string, values []any) (int, bool, error) {
+	if where == "" {
+		var estimate int
+		err := tx.QueryRow(ctx, `SELECT reltuples::bigint FROM pg_class WHERE oid = 'profiles'::regclass;`).Scan(&estimate)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if estimate >= estimateThreshold {
+			return estimate, true, nil
+		}
+	}
+
+	var total int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM profiles`+where+`;`, values...).Scan(&total); err != nil {
+		return 0, false, err
+	}
+
+	return total, false, nil
+}
+
+func (s *PStorage) TakeProfile(ctx context.Context, guid string) (models.Person, error) {
+	const op = "storage.postgres.profile.TakeProfile"
+
+	var person models.Person
+
+	row := s.pool.QueryRow(ctx, `SELECT `+profileColumns+` FROM profiles WHERE guid = $1;`, []byte(guid))
+	if err := scanPerson(row, &person); err != nil {
+		if errors.Is(err, pgxv4.ErrNoRows) {
+			return models.Person{}, fmt.Errorf("%s: %w", op, storage.ErrProfileNotFound)
+		}
+
+		return models.Person{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return person, nil
+}
+
+// profileColumns are the columns read into models.Person by scanPerson.
+const profileColumns = `guid, name, surname, patronymic, COALESCE(query_name, ''), COALESCE(country_hint, ''), COALESCE(age, 0), COALESCE(age_count, 0), COALESCE(gender::text, ''), COALESCE(gender_probability, 0), COALESCE(gender_count, 0), COALESCE(gender_source, ''), COALESCE(nationalize, ''), ` + countriesColumn + `, enrichment_status::text, pending_fields, low_confidence_fields, confidence_policy, provenance, created_at, updated_at`
+
+func scanPerson(row pgxv4.Row, item *models.Person) error {
+	var guid []byte
+
+	err := row.Scan(&guid, &item.Name, &item.Surname, &item.Patronymic, &item.QueryName, &item.CountryHint, &item.Age, &item.AgeCount, &item.Gender, &item.GenderProbability, &item.GenderCount, &item.GenderSource, &item.Nationalize, &item.Countries, &item.EnrichmentStatus, &item.PendingFields, &item.LowConfidence, &item.ConfidencePolicy, &item.Provenance, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	item.GUID = string(guid)
+
+	return nil
 }
 
 
@@ -135,14 +227,14 @@ func (s *PStorage) RemoveProfile(ctx context.Context, person models.DeletePerson
 		WHERE guid = $1;
 	`, person.GUID)
 
-	if cTag.RowsAffected() == 0 {
-		return nil, fmt.Errorf("%s: %w", op, storage.ErrProfileNotFound)
-	}
-
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if cTag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrProfileNotFound)
+	}
+
 	return []byte(person.GUID), nil
 }
 
@@ -169,6 +261,7 @@ func (s *PStorage) UpdateProfile(ctx context.Context, person models.UpdatedPerso
 
 	arguments, values, ind := []string{}, []any{}, 1
 	query := `UPDATE profiles SET `
+	var manual []string
 
 	if person.Name != "" || person.Surname != "" || person.Patronymic != "" || person.Age != 0 || person.Gender != "" || person.Nationalize != ""  {
 		if person.Name != "" {
@@ -187,24 +280,55 @@ func (s *PStorage) UpdateProfile(ctx context.Context, person models.UpdatedPerso
 			ind++
 		}	
 		if person.Age != 0 {
-			arguments = append(arguments, fmt.Sprintf(`age = $%d`, ind))
+			arguments = append(arguments, fmt.Sprintf(`age = $%d`, ind), `age_count = NULL`)
 			values = append(values, person.Age)
+			manual = append(manual, models.FieldAge)
 			ind++
 		}	
 		if person.Gender != "" {
-			arguments = append(arguments, fmt.Sprintf(`gender = $%d`, ind))
+			arguments = append(arguments, fmt.Sprintf(`gender = $%d`, ind), `gender_probability = NULL`, `gender_count = NULL`, fmt.Sprintf(`gender_source = '%s'`, models.GenderSourceManual))
 			values = append(values, person.Gender)
+			manual = append(manual, models.FieldGender)
 			ind++
 		}
 		if person.Nationalize != "" {
 			arguments = append(arguments, fmt.Sprintf(`nationalize = $%d`, ind))
 			values = append(values, person.Nationalize)
+			manual = append(manual, models.FieldNationalize)
+			ind++
+		}
+		// cleared must not be nil: provenance - NULL is NULL.
+		cleared := []string{}
+		if person.Replace {
+			arguments, cleared = replaceArguments(person, arguments)
+		}
+		if len(manual) > 0 {
+			arguments = append(arguments,
+				fmt.Sprintf(`pending_fields = ARRAY(SELECT unnest(pending_fields) EXCEPT SELECT unnest($%d::text[]))`, ind),
+				fmt.Sprintf(`enrichment_status = CASE WHEN pending_fields <@ $%d::text[] THEN 'complete' ELSE enrichment_status END`, ind),
+			)
+			values = append(values, manual)
 			ind++
 		}
+		if len(manual) > 0 || len(cleared) > 0 {
+			overrides := make(models.Provenance, len(manual))
+			for _, field := range manual {
+				overrides[field] = models.FieldProvenance{Kind: models.ProvenanceManual, Source: provenanceSourceAPI, UpdatedAt: time.Now().UTC()}
+			}
+
+			arguments = append(arguments,
+				fmt.Sprintf(`low_confidence_fields = ARRAY(SELECT unnest(low_confidence_fields) EXCEPT SELECT unnest($%d::text[]))`, ind),
+				fmt.Sprintf(`provenance = (provenance - $%d::text[]) || $%d::jsonb`, ind+1, ind+2),
+			)
+			values = append(values, append(slices.Clone(manual), cleared...), cleared, overrides)
+			ind += 3
+		}
 	} else {
 		return nil, fmt.Errorf("%s: %w", op, storage.ErrNoChanges)
 	}
 
+	arguments = append(arguments, `updated_at = now()`)
+
 	query += strings.Join(arguments, ",")
 	query += fmt.Sprintf(` WHERE guid = $%d RETURNING guid;`, ind)
 	values = append(values, []byte(person.GUID))
@@ -214,21 +338,52 @@ func (s *PStorage) UpdateProfile(ctx context.Context, person models.UpdatedPerso
 	err = row.Scan(&guid)
 
 	if err != nil {
-		if guid == nil {
+		if errors.Is(err, pgxv4.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, storage.ErrProfileNotFound)
 		}
 
-		if errors.Is(err, pgx.ErrNoRows){
-			return nil, fmt.Errorf("%s: %w", op, storage.ErrNoChanges)
-		}
-
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// A manual nationality has no probabilities, like a manual gender, so
+	// the countries of the last lookup are dropped with it. So are they when
+	// a replace clears the nationality.
+	if person.Nationalize != "" || person.Replace {
+		if err = saveCountries(ctx, tx, guid, nil); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	return guid, nil
 }
 
 
+// replaceArguments appends the assignments that clear the fields a replace
+// leaves out, and returns the enriched fields it clears. They lose their
+// statistics along with the value.
+func replaceArguments(person models.UpdatedPerson, arguments []string) ([]string, []string) {
+	cleared := []string{}
+
+	if person.Patronymic == "" {
+		arguments = append(arguments, `patronymic = NULL`)
+	}
+	if person.Age == 0 {
+		arguments = append(arguments, `age = NULL`, `age_count = NULL`, `inferred_age = NULL`)
+		cleared = append(cleared, models.FieldAge)
+	}
+	if person.Gender == "" {
+		arguments = append(arguments, `gender = NULL`, `gender_probability = NULL`, `gender_count = NULL`, `inferred_gender = NULL`, `gender_source = NULL`)
+		cleared = append(cleared, models.FieldGender)
+	}
+	if person.Nationalize == "" {
+		arguments = append(arguments, `nationalize = NULL`)
+		cleared = append(cleared, models.FieldNationalize)
+	}
+
+	return arguments, cleared
+}
+
+
 func (s *PStorage) NewProfile(ctx context.Context, person models.EnrichedPerson) (guid []byte, err error) {
 	const op = "storage.postgres.profile.NewProfile"
 
@@ -250,10 +405,24 @@ func (s *PStorage) NewProfile(ctx context.Context, person models.EnrichedPerson)
 	}()
 
 	row := tx.QueryRow(ctx, `
-		INSERT INTO profiles (guid, name, surname, patronymic, age, gender, nationalize)
-		VALUES($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO profiles (guid, name, surname, patronymic, age, age_count, gender, gender_probability, gender_count, nationalize, enrichment_status, pending_fields, inferred_age, inferred_gender, low_confidence_fields, confidence_policy, query_name, gender_source, country_hint, provenance)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING guid;
-	`, []byte(person.GUID), person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, person.Nationalize)
+	`, []byte(person.GUID), person.Name, person.Surname, person.Patronymic,
+		enrichedValue(person, models.FieldAge, person.Age),
+		enrichedValue(person, models.FieldAge, person.AgeCount),
+		enrichedValue(person, models.FieldGender, person.Gender),
+		enrichedValue(person, models.FieldGender, person.GenderProbability),
+		enrichedValue(person, models.FieldGender, person.GenderCount),
+		enrichedValue(person, models.FieldNationalize, person.Nationalize),
+		person.EnrichmentStatus, pendingFields(person.PendingFields),
+		enrichedValue(person, models.FieldAge, person.InferredAge),
+		enrichedValue(person, models.FieldGender, person.InferredGender),
+		pendingFields(person.LowConfidence), person.ConfidencePolicy,
+		enrichedValue(person, "", person.QueryName),
+		enrichedValue(person, models.FieldGender, person.GenderSource),
+		enrichedValue(person, "", person.CountryHint),
+		provenance(person.Provenance))
 
 	err = row.Scan(&guid)
 
@@ -261,5 +430,303 @@ func (s *PStorage) NewProfile(ctx context.Context, person models.EnrichedPerson)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if !slices.Contains(person.PendingFields, models.FieldNationalize) {
+		if err = saveCountries(ctx, tx, guid, person.Countries); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if len(person.PendingFields) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO enrichment_jobs (profile_guid)
+			VALUES ($1);
+		`, guid)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	return guid, nil
+}
+
+
+// UpdateEnrichment saves the fields that were pending on the profile.
+func (s *PStorage) UpdateEnrichment(ctx context.Context, person models.EnrichedPerson) error {
+	const op = "storage.postgres.profile.UpdateEnrichment"
+
+	return s.saveEnrichment(ctx, op, person, false)
+}
+
+// RefreshEnrichment saves every enriched field that was not set manually.
+func (s *PStorage) RefreshEnrichment(ctx context.Context, person models.EnrichedPerson) error {
+	const op = "storage.postgres.profile.RefreshEnrichment"
+
+	return s.saveEnrichment(ctx, op, person, true)
+}
+
+// EnrichmentTarget returns what is needed to re-enrich the profile.
+func (s *PStorage) EnrichmentTarget(ctx context.Context, guid string) (models.EnrichedPerson, error) {
+	const op = "storage.postgres.profile.EnrichmentTarget"
+
+	targets, err := s.enrichmentTargets(ctx, ` WHERE guid = $1`, []any{[]byte(guid)})
+	if err != nil {
+		return models.EnrichedPerson{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(targets) == 0 {
+		return models.EnrichedPerson{}, fmt.Errorf("%s: %w", op, storage.ErrProfileNotFound)
+	}
+
+	return targets[0], nil
+}
+
+// EnrichmentTargets returns the profiles matching the GetPerson filters.
+// Unlike TakeProfiles, every match is returned when PageSize is zero.
+func (s *PStorage) EnrichmentTargets(ctx context.Context, filter models.GetPerson) ([]models.EnrichedPerson, error) {
+	const op = "storage.postgres.profile.EnrichmentTargets"
+
+	where, values, err := profileFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	where += ` ORDER BY guid`
+
+	if filter.PageSize > 0 {
+		where += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(values)+1, len(values)+2)
+		values = append(values, filter.PageSize, max(filter.Page-1, 0)*filter.PageSize)
+	}
+
+	targets, err := s.enrichmentTargets(ctx, where, values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return targets, nil
+}
+
+func (s *PStorage) enrichmentTargets(ctx context.Context, where string, values []any) ([]models.EnrichedPerson, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT guid, name, surname, COALESCE(patronymic, ''), COALESCE(country_hint, ''), enrichment_status::text, pending_fields, provenance
+		FROM profiles`+where+`;`, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var targets []models.EnrichedPerson
+	for rows.Next() {
+		var (
+			person models.EnrichedPerson
+			guid   []byte
+		)
+
+		err = rows.Scan(&guid, &person.Name, &person.Surname, &person.Patronymic, &person.CountryHint, &person.EnrichmentStatus, &person.PendingFields, &person.Provenance)
+		if err != nil {
+			return nil, err
+		}
+
+		person.GUID = string(guid)
+		targets = append(targets, person)
+	}
+
+	return targets, rows.Err()
+}
+
+// saveEnrichment writes an enrichment result. Normally only fields that are
+// still pending are overwritten, so values set through UpdateProfile in the
+// meantime are kept. With refresh, every field that was not set manually is
+// overwritten unless its provider failed.
+func (s *PStorage) saveEnrichment(ctx context.Context, op string, person models.EnrichedPerson, refresh bool) (err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		commitErr := tx.Commit(ctx)
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	var (
+		pending, lowConfidence []string
+		current                models.Provenance
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT pending_fields, low_confidence_fields, provenance FROM profiles
+		WHERE guid = $1
+		FOR UPDATE;
+	`, []byte(person.GUID)).Scan(&pending, &lowConfidence, &current)
+	if err != nil {
+		if errors.Is(err, pgxv4.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrProfileNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	targets := pending
+	if refresh {
+		targets = nil
+		for _, field := range allFields {
+			if current[field].Kind != models.ProvenanceManual {
+				targets = append(targets, field)
+			}
+		}
+	}
+
+	resolved := func(field string) bool {
+		return slices.Contains(targets, field) && !slices.Contains(person.PendingFields, field)
+	}
+
+	var remaining []string
+	for _, field := range pending {
+		if slices.Contains(person.PendingFields, field) {
+			remaining = append(remaining, field)
+		}
+	}
+
+	status := models.EnrichmentPartial
+	switch len(remaining) {
+	case 0:
+		status = models.EnrichmentComplete
+	case len(allFields):
+		status = models.EnrichmentPending
+	}
+
+	var low []string
+	for _, field := range lowConfidence {
+		if !resolved(field) {
+			low = append(low, field)
+		}
+	}
+	for _, field := range person.LowConfidence {
+		if resolved(field) {
+			low = append(low, field)
+		}
+	}
+
+	updated := make(models.Provenance)
+	for field, p := range person.Provenance {
+		if resolved(field) {
+			updated[field] = p
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE profiles SET
+			age = CASE WHEN $2 THEN $3 ELSE age END,
+			age_count = CASE WHEN $2 THEN $4 ELSE age_count END,
+			inferred_age = CASE WHEN $2 THEN $5 ELSE inferred_age END,
+			gender = CASE WHEN $6 THEN $7::gen ELSE gender END,
+			gender_probability = CASE WHEN $6 THEN $8 ELSE gender_probability END,
+			gender_count = CASE WHEN $6 THEN $9 ELSE gender_count END,
+			inferred_gender = CASE WHEN $6 THEN $10 ELSE inferred_gender END,
+			nationalize = CASE WHEN $11 THEN $12 ELSE nationalize END,
+			pending_fields = $13,
+			enrichment_status = $14,
+			low_confidence_fields = $15,
+			confidence_policy = $16,
+			query_name = COALESCE($17, query_name),
+			gender_source = CASE WHEN $6 THEN $18 ELSE gender_source END,
+			provenance = provenance || $19::jsonb,
+			updated_at = now()
+		WHERE guid = $1;
+	`, []byte(person.GUID),
+		resolved(models.FieldAge),
+		enrichedValue(person, models.FieldAge, person.Age),
+		enrichedValue(person, models.FieldAge, person.AgeCount),
+		enrichedValue(person, models.FieldAge, person.InferredAge),
+		resolved(models.FieldGender),
+		enrichedValue(person, models.FieldGender, person.Gender),
+		enrichedValue(person, models.FieldGender, person.GenderProbability),
+		enrichedValue(person, models.FieldGender, person.GenderCount),
+		enrichedValue(person, models.FieldGender, person.InferredGender),
+		resolved(models.FieldNationalize),
+		enrichedValue(person, models.FieldNationalize, person.Nationalize),
+		pendingFields(remaining), status, pendingFields(low), person.ConfidencePolicy,
+		enrichedValue(person, "", person.QueryName),
+		enrichedValue(person, models.FieldGender, person.GenderSource),
+		updated)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if resolved(models.FieldNationalize) {
+		if err = saveCountries(ctx, tx, []byte(person.GUID), person.Countries); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// profileFilter builds the WHERE clause for the GetPerson filters. The
+// placeholders are numbered from $1.
+func profileFilter(person models.GetPerson) (string, []any, error) {
+	and := personFilter(person)
+	if len(and) == 0 {
+		return "", nil, nil
+	}
+
+	where, values, err := Where(and)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ` WHERE ` + where, values, nil
+}
+
+// provenanceSourceAPI is the source of values set through UpdateProfile.
+const provenanceSourceAPI = "api"
+
+var allFields = []string{models.FieldAge, models.FieldGender, models.FieldNationalize}
+
+// enrichedValue maps pending fields and unknown (zero) inferred values to NULL.
+func enrichedValue(person models.EnrichedPerson, field string, value any) any {
+	if slices.Contains(person.PendingFields, field) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case int:
+		if v == 0 {
+			return nil
+		}
+	case float32:
+		if v == 0 {
+			return nil
+		}
+	}
+
+	return value
+}
+
+func provenance(p models.Provenance) models.Provenance {
+	if p == nil {
+		return models.Provenance{}
+	}
+
+	return p
+}
+
+func pendingFields(fields []string) []string {
+	if fields == nil {
+		return []string{}
+	}
+
+	return fields
 }
\ No newline at end of file
@@ -3,7 +3,8 @@ package storage
 import "errors"
 
 var (
-	ErrProfilesNotFound = errors.New("profiles not found")
 	ErrNoChanges = errors.New("no changes or profile not found")
 	ErrProfileNotFound = errors.New("profile not found")
+	ErrJobNotFound = errors.New("enrichment job not found")
+	ErrCacheMiss = errors.New("enrichment cache miss")
 )
\ No newline at end of file
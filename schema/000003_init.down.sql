@@ -0,0 +1,5 @@
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS pending_fields,
+DROP COLUMN IF EXISTS enrichment_status;
+
+DROP TYPE IF EXISTS enrichment_status;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TYPE enrichment_status AS ENUM ('complete', 'partial', 'pending');
+
+ALTER TABLE profiles
+ADD COLUMN enrichment_status enrichment_status NOT NULL DEFAULT 'complete',
+ADD COLUMN pending_fields TEXT[] NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS enrichment_jobs_run_at;
+
+DROP TABLE IF EXISTS enrichment_jobs;
+
+ALTER TABLE profiles
+DROP CONSTRAINT IF EXISTS profiles_pkey;
\ No newline at end of file
@@ -0,0 +1,15 @@
+ALTER TABLE profiles
+ADD PRIMARY KEY ("guid");
+
+CREATE TABLE IF NOT EXISTS
+    enrichment_jobs (
+        "id" BIGSERIAL PRIMARY KEY,
+        "profile_guid" BYTEA NOT NULL UNIQUE REFERENCES profiles("guid") ON DELETE CASCADE,
+        "attempts" INT NOT NULL DEFAULT 0,
+        "run_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+        "failed" BOOLEAN NOT NULL DEFAULT false,
+        "last_error" TEXT,
+        "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX enrichment_jobs_run_at ON enrichment_jobs("run_at") WHERE NOT "failed";
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS enrichment_cache_expires_at;
+
+DROP TABLE IF EXISTS enrichment_cache;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS
+    enrichment_cache (
+        "key" TEXT PRIMARY KEY,
+        "result" JSONB NOT NULL,
+        "expires_at" TIMESTAMPTZ NOT NULL
+    );
+
+CREATE INDEX enrichment_cache_expires_at ON enrichment_cache("expires_at");
\ No newline at end of file
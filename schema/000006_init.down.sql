@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS profile_countries_probability;
+
+DROP INDEX IF EXISTS profiles_gender_probability;
+
+DROP TABLE IF EXISTS profile_countries;
+
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS gender_count,
+DROP COLUMN IF EXISTS gender_probability,
+DROP COLUMN IF EXISTS age_count;
\ No newline at end of file
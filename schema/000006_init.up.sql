@@ -0,0 +1,17 @@
+ALTER TABLE profiles
+ADD COLUMN age_count INT,
+ADD COLUMN gender_probability REAL,
+ADD COLUMN gender_count INT;
+
+CREATE TABLE IF NOT EXISTS
+    profile_countries (
+        "profile_guid" BYTEA NOT NULL REFERENCES profiles("guid") ON DELETE CASCADE,
+        "rank" INT NOT NULL,
+        "country_id" TEXT NOT NULL,
+        "probability" REAL NOT NULL,
+        PRIMARY KEY ("profile_guid", "rank")
+    );
+
+CREATE INDEX profiles_gender_probability ON profiles("gender_probability");
+
+CREATE INDEX profile_countries_probability ON profile_countries("rank", "probability");
\ No newline at end of file
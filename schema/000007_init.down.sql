@@ -0,0 +1,5 @@
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS confidence_policy,
+DROP COLUMN IF EXISTS low_confidence_fields,
+DROP COLUMN IF EXISTS inferred_gender,
+DROP COLUMN IF EXISTS inferred_age;
\ No newline at end of file
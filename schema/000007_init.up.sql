@@ -0,0 +1,5 @@
+ALTER TABLE profiles
+ADD COLUMN inferred_age INT,
+ADD COLUMN inferred_gender TEXT,
+ADD COLUMN low_confidence_fields TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN confidence_policy JSONB;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS query_name;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE profiles
+ADD COLUMN query_name TEXT;
\ No newline at end of file
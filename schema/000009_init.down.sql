@@ -0,0 +1,2 @@
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS gender_source;
\ No newline at end of file
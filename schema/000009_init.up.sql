@@ -0,0 +1,4 @@
+ALTER TABLE profiles
+ADD COLUMN gender_source TEXT;
+
+UPDATE profiles SET gender_source = 'provider' WHERE gender IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS country_hint;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE profiles
+ADD COLUMN country_hint TEXT;
@@ -0,0 +1,13 @@
+ALTER TABLE profiles
+ADD COLUMN provenance JSONB NOT NULL DEFAULT '{}';
+
+UPDATE profiles p SET provenance = (
+    SELECT COALESCE(jsonb_object_agg(f.field, jsonb_strip_nulls(jsonb_build_object(
+        'kind', CASE WHEN f.field = 'gender' AND p.gender_source = 'manual' THEN 'manual' ELSE 'inferred' END,
+        'source', CASE WHEN f.field = 'gender' AND p.gender_source IN ('patronymic', 'surname') THEN p.gender_source END
+    ))), '{}')
+    FROM unnest(ARRAY['age', 'gender', 'nationalize']) AS f(field)
+    WHERE (f.field = 'age' AND p.age IS NOT NULL)
+       OR (f.field = 'gender' AND p.gender IS NOT NULL)
+       OR (f.field = 'nationalize' AND p.nationalize IS NOT NULL)
+);
\ No newline at end of file
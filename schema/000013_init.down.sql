@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS profiles_surname_guid;
+DROP INDEX IF EXISTS profiles_name_guid;
+DROP INDEX IF EXISTS profiles_age_guid;
+DROP INDEX IF EXISTS profiles_created_at_guid;
+DROP INDEX IF EXISTS profiles_updated_at_guid;
@@ -0,0 +1,5 @@
+CREATE INDEX profiles_surname_guid ON profiles("surname", "guid");
+CREATE INDEX profiles_name_guid ON profiles("name", "guid");
+CREATE INDEX profiles_age_guid ON profiles("age", "guid");
+CREATE INDEX profiles_created_at_guid ON profiles("created_at", "guid");
+CREATE INDEX profiles_updated_at_guid ON profiles("updated_at", "guid");
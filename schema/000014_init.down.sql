@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS profiles_age_guid;
+CREATE INDEX profiles_age_guid ON profiles("age", "guid");
@@ -0,0 +1,12 @@
+UPDATE profiles SET enrichment_status = 'pending' WHERE enrichment_status = 'failed';
+
+ALTER TYPE enrichment_status RENAME TO enrichment_status_old;
+
+CREATE TYPE enrichment_status AS ENUM ('complete', 'partial', 'pending');
+
+ALTER TABLE profiles
+ALTER COLUMN enrichment_status DROP DEFAULT,
+ALTER COLUMN enrichment_status TYPE enrichment_status USING enrichment_status::text::enrichment_status,
+ALTER COLUMN enrichment_status SET DEFAULT 'complete';
+
+DROP TYPE enrichment_status_old;
@@ -0,0 +1 @@
+ALTER TYPE enrichment_status ADD VALUE IF NOT EXISTS 'failed';
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/lib/backoff"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/breaker"
+)
+
+func TestBreaker_StateMachine(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+
+	var changes []string
+
+	var b *breaker.Breaker
+	b = breaker.New(2, cooldown, func(from, to breaker.State) {
+		// Reading the status would deadlock if onChange ran under the lock.
+		if got := b.Status().State; got != to {
+			t.Errorf("status in onChange is %s, want %s", got, to)
+		}
+
+		changes = append(changes, from.String()+"->"+to.String())
+	})
+
+	fail := func() {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		b.Failure()
+	}
+
+	fail()
+	if got := b.Status().State; got != breaker.StateClosed {
+		t.Fatalf("opened below the threshold: %s", got)
+	}
+
+	fail()
+	if got := b.Status().State; got != breaker.StateOpen {
+		t.Fatalf("expected open, got %s", got)
+	}
+
+	if err := b.Allow(); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("expected ErrOpen during cooldown, got %v", err)
+	}
+
+	time.Sleep(cooldown)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected a probe after cooldown, got %v", err)
+	}
+
+	if err := b.Allow(); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("expected a single probe, got %v", err)
+	}
+
+	b.Failure()
+	if got := b.Status().State; got != breaker.StateOpen {
+		t.Fatalf("failed probe should reopen, got %s", got)
+	}
+
+	time.Sleep(cooldown)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.Success()
+
+	status := b.Status()
+	if status.State != breaker.StateClosed || status.Failures != 0 {
+		t.Fatalf("expected closed with no failures, got %+v", status)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("expected changes %v, got %v", want, changes)
+	}
+
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("expected changes %v, got %v", want, changes)
+		}
+	}
+}
+
+func TestBreaker_AbortReleasesProbe(t *testing.T) {
+	b := breaker.New(1, 0, nil)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.Failure()
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected a probe, got %v", err)
+	}
+
+	b.Abort()
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("aborted probe should be released, got %v", err)
+	}
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := backoff.Backoff{Base: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+
+	cases := []struct {
+		attempt int
+		ceil    time.Duration
+	}{
+		{0, 10 * time.Millisecond},
+		{1, 20 * time.Millisecond},
+		{2, 40 * time.Millisecond},
+		{3, 50 * time.Millisecond},
+		{10, 50 * time.Millisecond},
+	}
+
+	for _, tt := range cases {
+		for range 100 {
+			d := b.Delay(tt.attempt)
+			if d < 0 || d > tt.ceil {
+				t.Fatalf("attempt %d: delay %s outside [0, %s]", tt.attempt, d, tt.ceil)
+			}
+		}
+	}
+
+	if d := (backoff.Backoff{}).Delay(3); d != 0 {
+		t.Fatalf("zero backoff should not wait, got %s", d)
+	}
+}
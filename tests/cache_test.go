@@ -0,0 +1,318 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/cache"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/lru"
+	"github.com/stepan41k/Effective-Mobile/internal/storage"
+)
+
+type storedEntry struct {
+	payload   []byte
+	expiresAt time.Time
+}
+
+// fakeStore is an in-memory cache.Store.
+type fakeStore struct {
+	mu      sync.Mutex
+	entries map[string]storedEntry
+	purges  int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{entries: make(map[string]storedEntry)}
+}
+
+func (f *fakeStore) CachedEnrichment(ctx context.Context, key string) ([]byte, time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e, ok := f.entries[key]
+	if !ok || !time.Now().Before(e.expiresAt) {
+		return nil, time.Time{}, storage.ErrCacheMiss
+	}
+
+	return e.payload, e.expiresAt, nil
+}
+
+func (f *fakeStore) SaveCachedEnrichment(ctx context.Context, key string, payload []byte, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.entries[key] = storedEntry{payload: payload, expiresAt: expiresAt}
+
+	return nil
+}
+
+func (f *fakeStore) DeleteExpiredEnrichment(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.purges++
+
+	var deleted int64
+	for key, e := range f.entries {
+		if !time.Now().Before(e.expiresAt) {
+			delete(f.entries, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// countingEnricher answers with the number of the call as the age.
+type countingEnricher struct {
+	calls atomic.Int64
+	wait  chan struct{}
+}
+
+func (e *countingEnricher) Enrich(ctx context.Context, q enrichment.Query) (enrichment.Result, error) {
+	n := e.calls.Add(1)
+
+	if e.wait != nil {
+		<-e.wait
+	}
+
+	return enrichment.Result{Query: q.Name, Age: models.Age{Name: q.Name, Age: int(n)}}, nil
+}
+
+func newCache(next enrichment.Enricher, store cache.Store, ttl time.Duration) *cache.Cache {
+	return cache.New(slog.New(slog.NewTextHandler(io.Discard, nil)), next, store, 10, ttl)
+}
+
+func TestCache_HitAndMiss(t *testing.T) {
+	next := &countingEnricher{}
+	store := newFakeStore()
+	c := newCache(next, store, time.Hour)
+
+	ctx := context.Background()
+
+	first, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := c.Enrich(ctx, enrichment.Query{Name: " IVAN "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 1 || first.Age != second.Age {
+		t.Fatalf("expected a memory hit, got %d calls", next.calls.Load())
+	}
+
+	if _, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan", CountryID: "RU"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 2 {
+		t.Fatalf("a country hint should miss, got %d calls", next.calls.Load())
+	}
+
+	// A fresh cache over the same store hits the durable entry.
+	fresh := newCache(next, store, time.Hour)
+
+	third, err := fresh.Enrich(ctx, enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 2 || third.Age != first.Age {
+		t.Fatalf("expected a store hit, got %d calls", next.calls.Load())
+	}
+
+	stats := fresh.Stats()
+	if stats.StoreHits != 1 || stats.Misses != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if store.purges != 1 {
+		t.Fatalf("expected one purge per interval, got %d", store.purges)
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	next := &countingEnricher{}
+	c := newCache(next, newFakeStore(), 10*time.Millisecond)
+
+	ctx := context.Background()
+
+	if _, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	res, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 2 || res.Age.Age != 2 {
+		t.Fatalf("expired entry was served, got %d calls", next.calls.Load())
+	}
+}
+
+func TestCache_DecodeFailure(t *testing.T) {
+	next := &countingEnricher{}
+	store := newFakeStore()
+
+	key := cache.Key(enrichment.Query{Name: "Ivan"})
+	store.entries[key] = storedEntry{payload: []byte("{not json"), expiresAt: time.Now().Add(time.Hour)}
+
+	c := newCache(next, store, time.Hour)
+
+	res, err := c.Enrich(context.Background(), enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 1 || res.Age.Age != 1 {
+		t.Fatalf("a corrupt entry should be looked up again, got %d calls", next.calls.Load())
+	}
+
+	if string(store.entries[key].payload) == "{not json" {
+		t.Fatal("the corrupt entry was not replaced")
+	}
+}
+
+func TestCache_ConcurrentMisses(t *testing.T) {
+	next := &countingEnricher{wait: make(chan struct{})}
+	c := newCache(next, newFakeStore(), time.Hour)
+
+	const callers = 8
+
+	var wg sync.WaitGroup
+	results := make([]enrichment.Result, callers)
+	errs := make([]error, callers)
+
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			results[i], errs[i] = c.Enrich(context.Background(), enrichment.Query{Name: "Ivan"})
+		}()
+	}
+
+	// Let every caller reach the cache before the lookup returns.
+	time.Sleep(20 * time.Millisecond)
+	close(next.wait)
+	wg.Wait()
+
+	for i := range callers {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: unexpected error: %v", i, errs[i])
+		}
+
+		if results[i].Age != results[0].Age {
+			t.Fatalf("caller %d got a different answer", i)
+		}
+	}
+
+	if n := next.calls.Load(); n != 1 {
+		t.Fatalf("expected one lookup for concurrent misses, got %d", n)
+	}
+}
+
+func TestLRU_Eviction(t *testing.T) {
+	c := lru.New[string, int](2)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	// Reading a makes b the least recently used.
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %d, %v", v, ok)
+	}
+
+	c.Add("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b should have been evicted")
+	}
+
+	c.Add("a", 10)
+
+	if v, _ := c.Get("a"); v != 10 {
+		t.Fatalf("expected a to be updated, got %d", v)
+	}
+
+	c.Remove("c")
+
+	if _, ok := c.Get("c"); ok || c.Len() != 1 {
+		t.Fatalf("expected only a to remain, got len %d", c.Len())
+	}
+}
+
+func TestLRU_ZeroSize(t *testing.T) {
+	c := lru.New[string, int](0)
+
+	c.Add("a", 1)
+
+	if _, ok := c.Get("a"); ok || c.Len() != 0 {
+		t.Fatal("a zero-size cache should not keep entries")
+	}
+}
+
+func TestLRU_Concurrent(t *testing.T) {
+	c := lru.New[int, int](16)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range 1000 {
+				c.Add(i*1000+j, j)
+				c.Get(j)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if c.Len() != 16 {
+		t.Fatalf("expected 16 entries, got %d", c.Len())
+	}
+}
+
+func TestCache_Refresh(t *testing.T) {
+	next := &countingEnricher{}
+	c := newCache(next, newFakeStore(), time.Hour)
+
+	ctx := context.Background()
+
+	if _, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	refreshed, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan", Refresh: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 2 || refreshed.Age.Age != 2 {
+		t.Fatalf("a refresh should skip the cache, got %d calls", next.calls.Load())
+	}
+
+	res, err := c.Enrich(ctx, enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.calls.Load() != 2 || res.Age.Age != 2 {
+		t.Fatalf("expected the refreshed answer to be cached, got age %d", res.Age.Age)
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	musicService "github.com/stepan41k/Effective-Mobile/internal/service/profile"
+)
+
+// savedProfiles records the profiles the service saves. Other storage
+// calls are not expected.
+type savedProfiles struct {
+	musicService.Profile
+	saved []models.EnrichedPerson
+}
+
+func (s *savedProfiles) NewProfile(ctx context.Context, person models.EnrichedPerson) ([]byte, error) {
+	s.saved = append(s.saved, person)
+
+	return []byte(person.GUID), nil
+}
+
+func TestApplyConfidence(t *testing.T) {
+	thresholds := models.ConfidencePolicy{MinAgeCount: 100, MinGenderProbability: 0.8, MinCountryProbability: 0.5}
+
+	answer := func(ageCount int, genderProbability, countryProbability float32) enrichment.Result {
+		return enrichment.Result{
+			Age:         models.Age{Age: 30, Count: ageCount},
+			Gender:      models.Gender{Gender: "male", Probability: genderProbability},
+			Nationalize: models.Nationalize{Country: []models.Country{{CountryID: "RU", Probability: countryProbability}}},
+		}
+	}
+
+	cases := []struct {
+		title         string
+		lowGender     string
+		policy        string
+		res           enrichment.Result
+		err           error
+		age           int
+		gender        string
+		nationalize   string
+		lowConfidence []string
+	}{
+		{
+			title:       "Above every threshold",
+			res:         answer(500, 0.95, 0.7),
+			age:         30,
+			gender:      "male",
+			nationalize: "RU",
+		},
+		{
+			title:       "Exactly at the thresholds",
+			res:         answer(100, 0.8, 0.5),
+			age:         30,
+			gender:      "male",
+			nationalize: "RU",
+		},
+		{
+			title:         "Below every threshold is cleared",
+			lowGender:     models.LowConfidenceUnknown,
+			res:           answer(10, 0.6, 0.2),
+			lowConfidence: []string{models.FieldAge, models.FieldGender, models.FieldNationalize},
+		},
+		{
+			title:         "Low-confidence gender becomes other",
+			lowGender:     models.LowConfidenceOther,
+			res:           answer(500, 0.6, 0.7),
+			age:           30,
+			gender:        models.LowConfidenceOther,
+			nationalize:   "RU",
+			lowConfidence: []string{models.FieldGender},
+		},
+		{
+			title:         "Pending fields are not flagged",
+			policy:        musicService.PolicyPartial,
+			res:           enrichment.Result{Age: models.Age{Age: 30, Count: 10}},
+			err:           providerFailure(enrichment.ProviderGender, enrichment.ProviderNationalize),
+			lowConfidence: []string{models.FieldAge},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			policy := thresholds
+			policy.LowConfidenceGender = tt.lowGender
+
+			if policy.LowConfidenceGender == "" {
+				policy.LowConfidenceGender = models.LowConfidenceUnknown
+			}
+
+			if tt.policy == "" {
+				tt.policy = musicService.PolicyStrict
+			}
+
+			store := &savedProfiles{}
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			service := musicService.New(store, &stubEnricher{res: tt.res, err: tt.err}, tt.policy, policy, musicService.GenderRulesOff, "", log)
+
+			if _, err := service.NewProfile(context.Background(), models.NewPerson{Name: "Ivan", Surname: "Smith"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(store.saved) != 1 {
+				t.Fatalf("expected one saved profile, got %d", len(store.saved))
+			}
+
+			got := store.saved[0]
+
+			if got.Age != tt.age || got.Gender != tt.gender || got.Nationalize != tt.nationalize {
+				t.Fatalf("expected %d, %q, %q, got %d, %q, %q", tt.age, tt.gender, tt.nationalize, got.Age, got.Gender, got.Nationalize)
+			}
+
+			if !reflect.DeepEqual(got.LowConfidence, tt.lowConfidence) {
+				t.Fatalf("expected low confidence fields %v, got %v", tt.lowConfidence, got.LowConfidence)
+			}
+
+			// The raw answer is kept so that the thresholds can be applied
+			// again later.
+			if got.InferredAge != tt.res.Age.Age || got.InferredGender != tt.res.Gender.Gender || !reflect.DeepEqual(got.Countries, tt.res.Nationalize.Country) {
+				t.Fatalf("the raw answer was not kept: %+v", got)
+			}
+
+			if got.ConfidencePolicy == nil || *got.ConfidencePolicy != policy {
+				t.Fatalf("expected policy %+v, got %+v", policy, got.ConfidencePolicy)
+			}
+		})
+	}
+}
+
+func TestNewProfile_Deferred(t *testing.T) {
+	store := &savedProfiles{}
+	enricher := &stubEnricher{err: providerFailure(enrichment.ProviderAge)}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := musicService.New(store, enricher, musicService.PolicyDeferred, models.ConfidencePolicy{}, musicService.GenderRulesOff, "", log)
+
+	if _, err := service.NewProfile(context.Background(), models.NewPerson{Name: "Ivan", Surname: "Smith"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if enricher.calls != 0 {
+		t.Fatalf("expected no lookup on create, got %d", enricher.calls)
+	}
+
+	if len(store.saved) != 1 {
+		t.Fatalf("expected one saved profile, got %d", len(store.saved))
+	}
+
+	got := store.saved[0]
+
+	if got.EnrichmentStatus != models.EnrichmentPending {
+		t.Fatalf("expected status %q, got %q", models.EnrichmentPending, got.EnrichmentStatus)
+	}
+
+	if want := []string{models.FieldAge, models.FieldGender, models.FieldNationalize}; !reflect.DeepEqual(got.PendingFields, want) {
+		t.Fatalf("expected pending fields %v, got %v", want, got.PendingFields)
+	}
+}
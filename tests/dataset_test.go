@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/dataset"
+)
+
+const datasetCSV = `name,age,age_count,gender,gender_probability,gender_count,countries
+Ivan, 35, 1200, male, 0.99, 5000, RU:0.71;UA:0.12
+Sasha,,,,,,
+`
+
+const datasetJSON = `[
+	{"name": "Ivan", "age": 35, "age_count": 1200, "gender": "male", "gender_probability": 0.99, "gender_count": 5000,
+	 "countries": [{"country_id": "RU", "probability": 0.71}, {"country_id": "UA", "probability": 0.12}]},
+	{"name": "Sasha"}
+]`
+
+func writeDataset(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestDatasetLoad(t *testing.T) {
+	want := enrichment.Result{
+		Age:    models.Age{Name: " IVAN ", Age: 35, Count: 1200},
+		Gender: models.Gender{Name: " IVAN ", Gender: "male", Probability: 0.99, Count: 5000},
+		Nationalize: models.Nationalize{Name: " IVAN ", Country: []models.Country{
+			{CountryID: "RU", Probability: 0.71},
+			{CountryID: "UA", Probability: 0.12},
+		}},
+		Provenance: models.Provenance{
+			enrichment.ProviderAge:         {Kind: models.ProvenanceImported, Source: dataset.Source},
+			enrichment.ProviderGender:      {Kind: models.ProvenanceImported, Source: dataset.Source},
+			enrichment.ProviderNationalize: {Kind: models.ProvenanceImported, Source: dataset.Source},
+		},
+	}
+
+	for file, content := range map[string]string{"names.csv": datasetCSV, "names.JSON": datasetJSON} {
+		t.Run(file, func(t *testing.T) {
+			ds, err := dataset.Load(writeDataset(t, file, content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ds.Len() != 2 {
+				t.Fatalf("expected 2 names, got %d", ds.Len())
+			}
+
+			// Names are matched after folding case and whitespace.
+			got, err := ds.Enrich(context.Background(), enrichment.Query{Name: " IVAN "})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %+v, got %+v", want, got)
+			}
+
+			// A name without statistics fails every provider.
+			_, err = ds.Enrich(context.Background(), enrichment.Query{Name: "Sasha"})
+
+			var enrichErr *enrichment.Error
+			if !errors.As(err, &enrichErr) || len(enrichErr.FailedProviders()) != 3 {
+				t.Fatalf("expected every provider to fail, got %v", err)
+			}
+
+			_, err = ds.Enrich(context.Background(), enrichment.Query{Name: "Nobody"})
+			if !errors.Is(err, dataset.ErrNameNotFound) {
+				t.Fatalf("expected name not found, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDatasetLoad_FailCases(t *testing.T) {
+	cases := []struct {
+		title   string
+		file    string
+		content string
+	}{
+		{title: "Unsupported format", file: "names.txt", content: datasetCSV},
+		{title: "Malformed JSON", file: "names.json", content: `[{"name": `},
+		{title: "Wrong column count", file: "names.csv", content: "Ivan,35,1200\n"},
+		{title: "Not an integer", file: "names.csv", content: "Ivan,old,1200,male,0.99,5000,RU:0.71\n"},
+		{title: "Not a probability", file: "names.csv", content: "Ivan,35,1200,male,high,5000,RU:0.71\n"},
+		{title: "Malformed country", file: "names.csv", content: "Ivan,35,1200,male,0.99,5000,RU\n"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			if _, err := dataset.Load(writeDataset(t, tt.file, tt.content)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	if _, err := dataset.Load(filepath.Join(t.TempDir(), "missing.csv")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	for input, want := range map[string]string{
+		"Ivan":            "ivan",
+		"  IVAN  ":        "ivan",
+		"Anna\t Maria":    "anna maria",
+		"Пётр":            "пётр",
+		"":                "",
+		"Jean-Luc O'Neil": "jean-luc o'neil",
+	} {
+		if got := enrichment.NormalizeName(input); got != want {
+			t.Fatalf("%q: expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+// stubEnricher answers with res and err.
+type stubEnricher struct {
+	res   enrichment.Result
+	err   error
+	calls int
+}
+
+func (s *stubEnricher) Enrich(ctx context.Context, q enrichment.Query) (enrichment.Result, error) {
+	s.calls++
+
+	return s.res, s.err
+}
+
+func providerFailure(providers ...string) error {
+	failed := &enrichment.Error{}
+	for _, p := range providers {
+		failed.Providers = append(failed.Providers, &enrichment.ProviderError{Provider: p, Err: enrichment.ErrProviderUnavailable})
+	}
+
+	return failed
+}
+
+func TestChain_Fallback(t *testing.T) {
+	remote := &stubEnricher{
+		res: enrichment.Result{Age: models.Age{Age: 30}, Nationalize: models.Nationalize{Country: []models.Country{{CountryID: "US", Probability: 0.5}}}},
+		err: providerFailure(enrichment.ProviderGender),
+	}
+	local := &stubEnricher{
+		res: enrichment.Result{
+			Age:        models.Age{Age: 99},
+			Gender:     models.Gender{Gender: "female", Probability: 0.9},
+			Provenance: models.Provenance{enrichment.ProviderGender: {Kind: models.ProvenanceImported, Source: dataset.Source}},
+		},
+	}
+	unused := &stubEnricher{}
+
+	res, err := enrichment.Chain{remote, local, unused}.Enrich(context.Background(), enrichment.Query{Name: "Ann"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Age.Age != 30 || res.Gender.Gender != "female" || res.Nationalize.Country[0].CountryID != "US" {
+		t.Fatalf("expected age and nationality from the first and gender from the second, got %+v", res)
+	}
+
+	if res.Provenance[enrichment.ProviderGender].Source != dataset.Source {
+		t.Fatalf("expected the provenance of the fallback, got %+v", res.Provenance)
+	}
+
+	if unused.calls != 0 {
+		t.Fatal("the chain kept asking after every provider answered")
+	}
+}
+
+func TestChain_FailCases(t *testing.T) {
+	down := errors.New("connection refused")
+
+	first := &stubEnricher{err: down}
+	second := &stubEnricher{
+		res: enrichment.Result{Age: models.Age{Age: 30}},
+		err: providerFailure(enrichment.ProviderGender, enrichment.ProviderNationalize),
+	}
+
+	res, err := enrichment.Chain{first, second}.Enrich(context.Background(), enrichment.Query{Name: "Ann"})
+
+	var enrichErr *enrichment.Error
+	if !errors.As(err, &enrichErr) {
+		t.Fatalf("expected an enrichment error, got %v", err)
+	}
+
+	if want := []string{enrichment.ProviderGender, enrichment.ProviderNationalize}; !reflect.DeepEqual(enrichErr.FailedProviders(), want) {
+		t.Fatalf("expected %v to fail, got %v", want, enrichErr.FailedProviders())
+	}
+
+	if res.Age.Age != 30 {
+		t.Fatalf("expected the age of the second enricher, got %+v", res.Age)
+	}
+
+	_, err = enrichment.Chain{first}.Enrich(context.Background(), enrichment.Query{Name: "Ann"})
+	if !errors.Is(err, down) {
+		t.Fatalf("expected the error of the only enricher, got %v", err)
+	}
+}
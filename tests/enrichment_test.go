@@ -0,0 +1,395 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/remote"
+	"github.com/stepan41k/Effective-Mobile/internal/fakeenrich"
+	"github.com/stepan41k/Effective-Mobile/internal/service"
+	musicService "github.com/stepan41k/Effective-Mobile/internal/service/profile"
+)
+
+func newFakeClient(t *testing.T, opts fakeenrich.Options) (*remote.Client, *fakeenrich.Server) {
+	t.Helper()
+
+	srv, fake := fakeenrich.NewTestServer(opts)
+	t.Cleanup(srv.Close)
+
+	return remote.New(slog.New(slog.NewTextHandler(io.Discard, nil)), fakeConfig(srv.URL)), fake
+}
+
+// fakeConfig points the three providers at a fakeenrich server at url.
+func fakeConfig(url string) config.Enrichment {
+	return config.Enrichment{
+		AgeURL:             url + fakeenrich.PathAge + "?name=",
+		GenderURL:          url + fakeenrich.PathGender + "?name=",
+		NationalizeURL:     url + fakeenrich.PathNationalize + "?name=",
+		AgeTimeout:         time.Second,
+		GenderTimeout:      time.Second,
+		NationalizeTimeout: time.Second,
+		MaxRetries:         1,
+		RetryBaseDelay:     time.Millisecond,
+		RetryMaxDelay:      10 * time.Millisecond,
+		BreakerThreshold:   5,
+		BreakerCooldown:    time.Minute,
+		BatchSize:          10,
+		BatchWait:          5 * time.Millisecond,
+	}
+}
+
+func TestEnrich_HappyPath(t *testing.T) {
+	client, _ := newFakeClient(t, fakeenrich.Options{Seed: 42})
+
+	first, err := client.Enrich(context.Background(), enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := client.Enrich(context.Background(), enrichment.Query{Name: "Ivan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.Age.Age == 0 || first.Gender.Gender == "" || len(first.Nationalize.Country) == 0 {
+		t.Fatalf("incomplete result: %+v", first)
+	}
+
+	if first.Age != second.Age || first.Gender != second.Gender {
+		t.Fatalf("answers are not deterministic: %+v != %+v", first, second)
+	}
+}
+
+func TestEnrich_CountryHint(t *testing.T) {
+	client, fake := newFakeClient(t, fakeenrich.Options{Seed: 42})
+
+	countries := []string{"", "RU", "US"}
+
+	// The fake answers per country, so an answer for the wrong country_id
+	// differs from the one asked for directly.
+	want := make([]models.Age, len(countries))
+	for i, country := range countries {
+		fakeAnswer(t, fake, fakeenrich.PathAge+"?name=Ivan&country_id="+country, &want[i])
+	}
+
+	if want[0] == want[1] || want[0] == want[2] || want[1] == want[2] {
+		t.Fatalf("the fake gives the same answer for different countries: %+v", want)
+	}
+
+	// Queried concurrently, the names land in one batch window and must be
+	// split per country.
+	batched := make([]enrichment.Result, len(countries))
+	errs := make([]error, len(countries))
+
+	var wg sync.WaitGroup
+	for i, country := range countries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			batched[i], errs[i] = client.Enrich(context.Background(), enrichment.Query{Name: "Ivan", CountryID: country})
+		}()
+	}
+
+	wg.Wait()
+
+	for i, country := range countries {
+		if errs[i] != nil {
+			t.Fatalf("country %q: unexpected error: %v", country, errs[i])
+		}
+
+		single, err := client.Enrich(context.Background(), enrichment.Query{Name: "Ivan", CountryID: country})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if batched[i].Age != want[i] {
+			t.Fatalf("country %q: batched answer %+v, want %+v", country, batched[i].Age, want[i])
+		}
+
+		if single.Age != want[i] {
+			t.Fatalf("country %q: single answer %+v, want %+v", country, single.Age, want[i])
+		}
+	}
+}
+
+func TestEnrich_Batching(t *testing.T) {
+	const names = 25
+
+	fake := fakeenrich.New(fakeenrich.Options{Seed: 42})
+
+	var (
+		mu       sync.Mutex
+		requests = make(map[string][]int)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path] = append(requests[r.URL.Path], len(r.URL.Query()["name[]"]))
+		mu.Unlock()
+
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	// The batch size is above what the APIs accept and the window is long
+	// enough for every lookup to be queued before it closes.
+	cfg := fakeConfig(srv.URL)
+	cfg.BatchSize = 50
+	cfg.BatchWait = 200 * time.Millisecond
+
+	client := remote.New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+
+	results := make([]enrichment.Result, names)
+	errs := make([]error, names)
+
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			results[i], errs[i] = client.Enrich(context.Background(), enrichment.Query{Name: fmt.Sprintf("Name%d", i)})
+		}()
+	}
+
+	wg.Wait()
+
+	for i := range names {
+		if errs[i] != nil {
+			t.Fatalf("name %d: unexpected error: %v", i, errs[i])
+		}
+
+		var want models.Age
+		fakeAnswer(t, fake, fmt.Sprintf("%s?name=Name%d", fakeenrich.PathAge, i), &want)
+
+		if results[i].Age != want {
+			t.Fatalf("name %d: batched answer %+v, want %+v", i, results[i].Age, want)
+		}
+	}
+
+	for _, path := range []string{fakeenrich.PathAge, fakeenrich.PathGender, fakeenrich.PathNationalize} {
+		sizes := requests[path]
+		slices.Sort(sizes)
+
+		if want := []int{5, 10, 10}; !slices.Equal(sizes, want) {
+			t.Fatalf("%s: expected batches of %v names, got %v", path, want, sizes)
+		}
+	}
+}
+
+func TestEnrich_RateLimited(t *testing.T) {
+	cases := []struct {
+		title      string
+		retryAfter time.Duration
+		retries    int
+	}{
+		{
+			title:      "Retry-After beyond the backoff ceiling",
+			retryAfter: time.Minute,
+			retries:    1,
+		},
+		{
+			title:   "Retries used up",
+			retries: 0,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			srv, _ := fakeenrich.NewTestServer(fakeenrich.Options{
+				Fault:         fakeenrich.FaultRateLimit,
+				FaultProvider: enrichment.ProviderAge,
+				RetryAfter:    tt.retryAfter,
+			})
+			t.Cleanup(srv.Close)
+
+			cfg := fakeConfig(srv.URL)
+			cfg.MaxRetries = tt.retries
+
+			client := remote.New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+
+			_, err := client.Enrich(context.Background(), enrichment.Query{Name: "Anna"})
+
+			var rateErr *enrichment.RateLimitError
+			if !errors.As(err, &rateErr) {
+				t.Fatalf("expected rate limit error, got %v", err)
+			}
+
+			if !rateErr.Until.After(time.Now()) {
+				t.Fatalf("expected the limit to end in the future, got %v", rateErr.Until)
+			}
+		})
+	}
+}
+
+func TestNewProfile_ProviderTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	cases := []struct {
+		title   string
+		policy  string
+		wantErr bool
+	}{
+		{
+			title:   "Strict policy rejects the profile",
+			policy:  musicService.PolicyStrict,
+			wantErr: true,
+		},
+		{
+			title:  "Partial policy leaves the slow field pending",
+			policy: musicService.PolicyPartial,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			srv, _ := fakeenrich.NewTestServer(fakeenrich.Options{
+				Fault:         fakeenrich.FaultLatency,
+				FaultProvider: enrichment.ProviderAge,
+				Latency:       10 * time.Second,
+			})
+			t.Cleanup(srv.Close)
+
+			cfg := fakeConfig(srv.URL)
+			cfg.AgeTimeout = timeout
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			store := &savedProfiles{}
+			profiles := musicService.New(store, remote.New(log, cfg), tt.policy, models.ConfidencePolicy{LowConfidenceGender: models.LowConfidenceUnknown}, musicService.GenderRulesOff, "", log)
+
+			start := time.Now()
+			_, err := profiles.NewProfile(context.Background(), models.NewPerson{Name: "Ivan", Surname: "Smith"})
+			elapsed := time.Since(start)
+
+			// Two timed out calls with one retry between them, plus slack.
+			if limit := time.Duration(cfg.MaxRetries+1)*timeout + time.Second; elapsed > limit {
+				t.Fatalf("create took %v, expected under %v", elapsed, limit)
+			}
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if len(store.saved) != 1 || !slices.Equal(store.saved[0].PendingFields, []string{models.FieldAge}) {
+					t.Fatalf("expected a profile with age pending, got %+v", store.saved)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, service.ErrEnrichmentFailed) || !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected an enrichment timeout, got %v", err)
+			}
+
+			var enrichErr *enrichment.Error
+			if !errors.As(err, &enrichErr) || !slices.Equal(enrichErr.FailedProviders(), []string{enrichment.ProviderAge}) {
+				t.Fatalf("expected only age to fail, got %v", err)
+			}
+
+			if len(store.saved) != 0 {
+				t.Fatalf("expected no saved profile, got %+v", store.saved)
+			}
+		})
+	}
+}
+
+// fakeAnswer decodes the answer of the fake to a single-name query into v.
+func fakeAnswer(t *testing.T, fake *fakeenrich.Server, target string, v any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	fake.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: unexpected status %d", target, rec.Code)
+	}
+
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("%s: %v", target, err)
+	}
+}
+
+func TestEnrich_FailCases(t *testing.T) {
+	cases := []struct {
+		title    string
+		fault    fakeenrich.Fault
+		provider string
+		failed   []string
+	}{
+		{
+			title:    "Server error on one provider",
+			fault:    fakeenrich.FaultServerError,
+			provider: enrichment.ProviderAge,
+			failed:   []string{enrichment.ProviderAge},
+		},
+		{
+			title:  "Malformed JSON on every provider",
+			fault:  fakeenrich.FaultMalformed,
+			failed: []string{enrichment.ProviderAge, enrichment.ProviderGender, enrichment.ProviderNationalize},
+		},
+		{
+			title:    "Rate limited provider",
+			fault:    fakeenrich.FaultRateLimit,
+			provider: enrichment.ProviderGender,
+			failed:   []string{enrichment.ProviderGender},
+		},
+		{
+			title:    "Empty country list",
+			fault:    fakeenrich.FaultEmptyCountry,
+			provider: enrichment.ProviderNationalize,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			client, _ := newFakeClient(t, fakeenrich.Options{Fault: tt.fault, FaultProvider: tt.provider, RetryAfter: time.Minute})
+
+			res, err := client.Enrich(context.Background(), enrichment.Query{Name: "Anna"})
+
+			for _, provider := range []string{enrichment.ProviderAge, enrichment.ProviderGender, enrichment.ProviderNationalize} {
+				_, ok := res.Provenance[provider]
+				if ok == slices.Contains(tt.failed, provider) {
+					t.Fatalf("unexpected provenance for %s: %v", provider, res.Provenance)
+				}
+			}
+
+			var enrichErr *enrichment.Error
+			if len(tt.failed) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.As(err, &enrichErr) {
+				t.Fatalf("expected enrichment error, got %v", err)
+			}
+
+			if got := enrichErr.FailedProviders(); len(got) != len(tt.failed) {
+				t.Fatalf("expected failed providers %v, got %v", tt.failed, got)
+			}
+
+			for _, provider := range tt.failed {
+				if !enrichErr.Failed(provider) {
+					t.Fatalf("expected %s to fail, got %v", provider, enrichErr.FailedProviders())
+				}
+			}
+		})
+	}
+}
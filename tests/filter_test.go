@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/storage/postgres"
+)
+
+func TestFilterParse_HappyPath(t *testing.T) {
+	q, err := url.ParseQuery("age[gte]=20&age[lt]=40&gender[in]=male,female&nationalize[ne]=RU&name[prefix]=Iv&surname[contains]=ov&page=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := filter.Parse(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := filter.And{
+		filter.Condition{Field: filter.FieldAge, Op: filter.OpGte, Values: []any{20}},
+		filter.Condition{Field: filter.FieldAge, Op: filter.OpLt, Values: []any{40}},
+		filter.Condition{Field: filter.FieldGender, Op: filter.OpIn, Values: []any{"male", "female"}},
+		filter.Condition{Field: filter.FieldName, Op: filter.OpPrefix, Values: []any{"Iv"}},
+		filter.Condition{Field: filter.FieldNationalize, Op: filter.OpNe, Values: []any{"RU"}},
+		filter.Condition{Field: filter.FieldSurname, Op: filter.OpContains, Values: []any{"ov"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFilterParse_FailCases(t *testing.T) {
+	cases := []struct {
+		title string
+		query string
+	}{
+		{title: "Unknown field", query: "height[gte]=180"},
+		{title: "Unsupported operator", query: "age[prefix]=2"},
+		{title: "Unknown operator", query: "name[like]=Iv"},
+		{title: "Not an integer", query: "age[gte]=twenty"},
+		{title: "Not a number", query: "gender_probability[gte]=high"},
+		{title: "Empty value", query: "name[contains]="},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := filter.Parse(q); !errors.Is(err, filter.ErrInvalid) {
+				t.Fatalf("expected invalid filter error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	got, err := filter.ParseSort("surname,-age")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := filter.Sort{{Field: filter.FieldSurname}, {Field: filter.FieldAge, Desc: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if got.String() != "surname,-age" {
+		t.Fatalf("unexpected string form %q", got.String())
+	}
+
+	for _, invalid := range []string{"guid", "-password", "age,-age", "surname,"} {
+		if _, err := filter.ParseSort(invalid); !errors.Is(err, filter.ErrInvalid) {
+			t.Fatalf("%q: expected invalid filter error, got %v", invalid, err)
+		}
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := filter.Cursor{Sort: "surname,-age", Values: []any{"Wick", float64(28)}, GUID: "ewqehQWE231u-Snu3h21sj-321s", Before: true}
+
+	var got filter.Cursor
+	if err := got.UnmarshalText([]byte(cursor.String())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, cursor) {
+		t.Fatalf("expected %v, got %v", cursor, got)
+	}
+}
+
+func TestCursor_Malformed(t *testing.T) {
+	for _, token := range []string{"", "not base64!", "bnVsbA", "e30"} {
+		if _, err := filter.DecodeCursor(token); !errors.Is(err, filter.ErrInvalid) {
+			t.Fatalf("%q: expected ErrInvalid, got %v", token, err)
+		}
+	}
+}
+
+func TestWhere(t *testing.T) {
+	cond := func(field filter.Field, op filter.Op, values ...any) filter.Condition {
+		return filter.Condition{Field: field, Op: op, Values: values}
+	}
+
+	cases := []struct {
+		title string
+		expr  filter.Expr
+		where string
+		args  []any
+	}{
+		{
+			title: "Equal",
+			expr:  cond(filter.FieldName, filter.OpEq, "Ivan"),
+			where: `name = $1`,
+			args:  []any{"Ivan"},
+		},
+		{
+			title: "Not equal matches NULL",
+			expr:  cond(filter.FieldPatronymic, filter.OpNe, "Ivanovich"),
+			where: `patronymic IS DISTINCT FROM $1`,
+			args:  []any{"Ivanovich"},
+		},
+		{
+			title: "Prefix escapes wildcards",
+			expr:  cond(filter.FieldName, filter.OpPrefix, `50%_off\`),
+			where: `name LIKE $1`,
+			args:  []any{`50\%\_off\\%`},
+		},
+		{
+			title: "Contains",
+			expr:  cond(filter.FieldSurname, filter.OpContains, "ov"),
+			where: `surname LIKE $1`,
+			args:  []any{`%ov%`},
+		},
+		{
+			title: "In strings",
+			expr:  cond(filter.FieldGender, filter.OpIn, "male", "female"),
+			where: `gender::text = ANY($1)`,
+			args:  []any{[]string{"male", "female"}},
+		},
+		{
+			title: "In ints",
+			expr:  cond(filter.FieldAge, filter.OpIn, 20, 30),
+			where: `age = ANY($1)`,
+			args:  []any{[]int{20, 30}},
+		},
+		{
+			title: "In reals",
+			expr:  cond(filter.FieldGenderProbability, filter.OpIn, 0.5, 0.9),
+			where: `gender_probability = ANY($1::real[])`,
+			args:  []any{[]float64{0.5, 0.9}},
+		},
+		{
+			title: "Real comparison",
+			expr:  cond(filter.FieldGenderProbability, filter.OpGte, 0.9),
+			where: `gender_probability >= $1::real`,
+			args:  []any{0.9},
+		},
+		{
+			title: "Country probability",
+			expr:  cond(filter.FieldCountryProbability, filter.OpLt, 0.5),
+			where: `(SELECT c.probability FROM profile_countries c WHERE c.profile_guid = profiles.guid AND c.rank = 0) < $1::real`,
+			args:  []any{0.5},
+		},
+		{
+			title: "And numbers placeholders in order",
+			expr: filter.And{
+				cond(filter.FieldAge, filter.OpGte, 20),
+				cond(filter.FieldAge, filter.OpLt, 40),
+				cond(filter.FieldNationalize, filter.OpEq, "RU"),
+			},
+			where: `(age >= $1 AND age < $2 AND nationalize = $3)`,
+			args:  []any{20, 40, "RU"},
+		},
+		{
+			title: "Empty and",
+			expr:  filter.And{},
+			where: `TRUE`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			where, args, err := postgres.Where(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if where != tt.where {
+				t.Fatalf("expected %s, got %s", tt.where, where)
+			}
+
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Fatalf("expected args %#v, got %#v", tt.args, args)
+			}
+		})
+	}
+}
+
+func TestWhere_FailCases(t *testing.T) {
+	cases := []struct {
+		title string
+		expr  filter.Expr
+	}{
+		{title: "Unknown field", expr: filter.Condition{Field: "height", Op: filter.OpEq, Values: []any{180}}},
+		{title: "Unknown operator", expr: filter.Condition{Field: filter.FieldAge, Op: "like", Values: []any{20}}},
+		{title: "No value", expr: filter.Condition{Field: filter.FieldName, Op: filter.OpEq}},
+		{title: "Nested error", expr: filter.And{filter.Condition{Field: "height", Op: filter.OpEq, Values: []any{180}}}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			if _, _, err := postgres.Where(tt.expr); !errors.Is(err, filter.ErrInvalid) {
+				t.Fatalf("expected invalid filter error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/app"
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/fakeenrich"
+	enrichmentHandler "github.com/stepan41k/Effective-Mobile/internal/http-server/handlers/enrichment"
+	musicHandler "github.com/stepan41k/Effective-Mobile/internal/http-server/handlers/profile"
+	"github.com/stepan41k/Effective-Mobile/internal/storage/postgres"
+)
+
+// host is the service the HTTP suites talk to. With TEST_STORAGE_PATH set,
+// TestMain starts the service in-process against that database and the
+// fake enrichment APIs, so the suites do not depend on the live ones.
+var host = "localhost:8082"
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	path := os.Getenv("TEST_STORAGE_PATH")
+	if path == "" {
+		return m.Run()
+	}
+
+	url, stop, err := startService(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start the service: %v\n", err)
+
+		return 1
+	}
+	defer stop()
+
+	host = strings.TrimPrefix(url, "http://")
+
+	return m.Run()
+}
+
+// startService serves the profile routes backed by the database at path,
+// with enrichment answered by fakeenrich. stop shuts both servers down and
+// closes the pool.
+func startService(path string) (url string, stop func(), err error) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	pool, err := postgres.New(context.Background(), path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fake := httptest.NewServer(fakeenrich.New(fakeenrich.Options{Seed: 1}))
+
+	cfg := config.Enrichment{
+		Policy:             "strict",
+		Providers:          []string{"remote"},
+		Transliteration:    "icao",
+		GenderRules:        "fallback",
+		AgeURL:             fake.URL + fakeenrich.PathAge + "?name=",
+		GenderURL:          fake.URL + fakeenrich.PathGender + "?name=",
+		NationalizeURL:     fake.URL + fakeenrich.PathNationalize + "?name=",
+		AgeTimeout:         time.Second,
+		GenderTimeout:      time.Second,
+		NationalizeTimeout: time.Second,
+		MaxRetries:         1,
+		RetryBaseDelay:     time.Millisecond,
+		RetryMaxDelay:      10 * time.Millisecond,
+		BreakerThreshold:   5,
+		BreakerCooldown:    time.Minute,
+		BatchSize:          10,
+		BatchWait:          5 * time.Millisecond,
+		CacheSize:          100,
+		CacheTTL:           time.Hour,
+		Confidence:         config.Confidence{LowConfidenceGender: "unknown"},
+	}
+
+	enrich, err := app.NewEnrichment(log, cfg, pool)
+	if err != nil {
+		fake.Close()
+		postgres.Close(context.Background(), pool)
+
+		return "", nil, err
+	}
+
+	service := app.NewProfileService(log, cfg, pool, enrich.Enricher)
+	router := app.NewRouter(musicHandler.New(service, log), enrichmentHandler.New(enrich.Client, enrich.Cache, log))
+
+	srv := httptest.NewServer(router)
+
+	return srv.URL, func() {
+		srv.Close()
+		fake.Close()
+		postgres.Close(context.Background(), pool)
+	}, nil
+}
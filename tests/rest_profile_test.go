@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+)
+
+func TestProfilesResource_HappyPath(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	name := gofakeit.FirstName()
+	surname := gofakeit.LastName()
+
+	created := e.POST("/api/v1/profiles").
+		WithJSON(models.NewPerson{
+			Name:    name,
+			Surname: surname,
+		}).
+		Expect().
+		Status(http.StatusCreated)
+
+	guid := created.JSON().Object().Value("data").String().Raw()
+	location := created.Header("Location")
+	location.IsEqual("/api/v1/profiles/" + guid)
+
+	e.GET(location.Raw()).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("data").Object().Value("name").IsEqual(name)
+
+	e.GET("/api/v1/profiles").
+		WithQuery("name", name).
+		WithQuery("surname", surname).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("data").Array().NotEmpty()
+
+	e.PATCH(location.Raw()).
+		WithJSON(models.PatchedPerson{
+			Age: gofakeit.Number(10, 80),
+		}).
+		Expect().
+		Status(http.StatusOK)
+
+	e.PUT(location.Raw()).
+		WithJSON(models.ReplacedPerson{
+			Name:    gofakeit.FirstName(),
+			Surname: gofakeit.LastName(),
+		}).
+		Expect().
+		Status(http.StatusOK)
+
+	e.DELETE(location.Raw()).
+		Expect().
+		Status(http.StatusNoContent)
+
+	e.GET(location.Raw()).
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestLegacyRoutes_Deprecated(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	resp := e.POST("/profile/new").
+		WithJSON(models.NewPerson{
+			Name:    gofakeit.FirstName(),
+			Surname: gofakeit.LastName(),
+		}).
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Deprecation").NotEmpty()
+	resp.Header("Link").Contains(`rel="successor-version"`)
+
+	// The resource routes are not mirrored under /profile, so a wrong
+	// method on a legacy route is not mistaken for a GUID.
+	e.GET("/profile/take").
+		Expect().
+		Status(http.StatusMethodNotAllowed)
+}
+
+func TestProfilesResource_Meta(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	name := gofakeit.LetterN(16)
+
+	for range 3 {
+		e.POST("/api/v1/profiles").
+			WithJSON(models.NewPerson{
+				Name:    name,
+				Surname: gofakeit.LastName(),
+			}).
+			Expect().
+			Status(http.StatusCreated)
+	}
+
+	first := e.GET("/api/v1/profiles").
+		WithQuery("name", name).
+		WithQuery("page_size", 2).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	first.Value("data").Array().Length().IsEqual(2)
+
+	meta := first.Value("meta").Object()
+	meta.Value("total").IsEqual(3)
+	meta.Value("page").IsEqual(1)
+	meta.Value("page_size").IsEqual(2)
+	meta.Value("has_more").IsEqual(true)
+
+	last := e.GET("/api/v1/profiles").
+		WithQuery("name", name).
+		WithQuery("page_size", 2).
+		WithQuery("cursor", first.Value("next_cursor").String().Raw()).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	last.Value("data").Array().Length().IsEqual(1)
+	last.Value("meta").Object().Value("has_more").IsEqual(false)
+	last.Value("meta").Object().NotContainsKey("page")
+	last.Value("prev_cursor").String().NotEmpty()
+
+	empty := e.POST("/profile/take").
+		WithJSON(models.GetPerson{
+			Name:     gofakeit.LetterN(16),
+			Page:     1,
+			PageSize: 10,
+		}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	empty.Value("data").Array().IsEmpty()
+	empty.Value("meta").Object().Value("total").IsEqual(0)
+}
+
+func TestProfilesResource_ReenrichKeepsManualFields(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	location := e.POST("/api/v1/profiles").
+		WithJSON(models.NewPerson{
+			Name:    gofakeit.FirstName(),
+			Surname: gofakeit.LastName(),
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		Header("Location").Raw()
+
+	const (
+		age    = 7
+		gender = "female"
+	)
+
+	e.PATCH(location).
+		WithJSON(models.PatchedPerson{
+			Age:    age,
+			Gender: gender,
+		}).
+		Expect().
+		Status(http.StatusOK)
+
+	result := e.POST(location + "/enrich").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("data").Object()
+
+	result.Value("refreshed_fields").Array().IsEqual([]string{models.FieldNationalize})
+	result.Value("skipped_fields").Array().ContainsOnly(models.FieldAge, models.FieldGender)
+
+	profile := e.GET(location).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("data").Object()
+
+	profile.Value("age").IsEqual(age)
+	profile.Value("gender").IsEqual(gender)
+
+	provenance := profile.Value("provenance").Object()
+	provenance.Value(models.FieldAge).Object().Value("kind").IsEqual(models.ProvenanceManual)
+	provenance.Value(models.FieldGender).Object().Value("kind").IsEqual(models.ProvenanceManual)
+	provenance.Value(models.FieldNationalize).Object().Value("kind").IsEqual(models.ProvenanceInferred)
+}
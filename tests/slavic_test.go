@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment/slavic"
+)
+
+func TestInferGender(t *testing.T) {
+	cases := []struct {
+		title      string
+		surname    string
+		patronymic string
+		countries  []string
+		gender     string
+		source     string
+	}{
+		{title: "Female patronymic", surname: "Smith", patronymic: "Ivanovna", gender: "female", source: models.GenderSourcePatronymic},
+		{title: "Longest patronymic suffix wins", patronymic: "Ilyinichna", gender: "female", source: models.GenderSourcePatronymic},
+		{title: "Male patronymic", patronymic: "Petrovich", gender: "male", source: models.GenderSourcePatronymic},
+		{title: "Cyrillic patronymic", patronymic: "Сергеевна", gender: "female", source: models.GenderSourcePatronymic},
+		{title: "Patronymic beats surname", surname: "Ivanova", patronymic: "Petrovich", countries: []string{"RU"}, gender: "male", source: models.GenderSourcePatronymic},
+		{title: "Cyrillic surname without country", surname: "Иванова", gender: "female", source: models.GenderSourceSurname},
+		{title: "Cyrillic male surname", surname: "Смирнов", gender: "male", source: models.GenderSourceSurname},
+		{title: "Latin surname with Slavic hint", surname: "Ivanova", countries: []string{"ru"}, gender: "female", source: models.GenderSourceSurname},
+		{title: "Latin surname with Slavic nationality", surname: "Dostoevsky", countries: []string{"", "RU"}, gender: "male", source: models.GenderSourceSurname},
+		{title: "Latin surname without country", surname: "Ivanova"},
+		{title: "Spanish surname", surname: "Medina", countries: []string{"ES"}},
+		{title: "Spanish surname without country", surname: "Molina"},
+		{title: "Italian surname", surname: "Casanova", countries: []string{"IT", "IT"}},
+		{title: "English surname", surname: "Franklin", countries: []string{"US"}},
+		{title: "French surname", surname: "Martin"},
+		{title: "Suffix alone", surname: "ов"},
+		{title: "Empty", countries: []string{"RU"}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			got, ok := slavic.InferGender(tt.surname, tt.patronymic, tt.countries...)
+
+			if tt.gender == "" {
+				if ok {
+					t.Fatalf("expected no inference, got %+v", got)
+				}
+
+				return
+			}
+
+			if !ok || got.Gender != tt.gender || got.Source != tt.source {
+				t.Fatalf("expected %s from %s, got %+v, %v", tt.gender, tt.source, got, ok)
+			}
+		})
+	}
+}
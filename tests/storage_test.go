@@ -0,0 +1,398 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	musicService "github.com/stepan41k/Effective-Mobile/internal/service/profile"
+	"github.com/stepan41k/Effective-Mobile/internal/storage"
+	"github.com/stepan41k/Effective-Mobile/internal/storage/postgres"
+)
+
+// newStorage connects to the migrated database named by TEST_STORAGE_PATH.
+// Tests that need it are skipped when it is not set.
+func newStorage(t *testing.T) *postgres.PStorage {
+	t.Helper()
+
+	path := os.Getenv("TEST_STORAGE_PATH")
+	if path == "" {
+		t.Skip("TEST_STORAGE_PATH is not set")
+	}
+
+	s, err := postgres.New(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { postgres.Close(context.Background(), s) })
+
+	return s
+}
+
+// newStoredProfile saves person under a fresh GUID and removes it when the
+// test ends.
+func newStoredProfile(t *testing.T, s *postgres.PStorage, person models.EnrichedPerson) string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	person.GUID = gofakeit.UUID()
+	if person.Name == "" {
+		person.Name = gofakeit.FirstName()
+	}
+
+	if person.Surname == "" {
+		person.Surname = gofakeit.LastName()
+	}
+
+	if person.EnrichmentStatus == "" {
+		person.EnrichmentStatus = models.EnrichmentComplete
+	}
+
+	if _, err := s.NewProfile(ctx, person); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = s.RemoveProfile(context.Background(), models.DeletePerson{GUID: person.GUID})
+	})
+
+	return person.GUID
+}
+
+func TestJobQueue_ClaimAndFail(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	guid := newStoredProfile(t, s, models.EnrichedPerson{
+		EnrichmentStatus: models.EnrichmentPending,
+		PendingFields:    []string{models.FieldAge, models.FieldGender, models.FieldNationalize},
+	})
+
+	claim := func() (models.EnrichmentJob, bool) {
+		jobs, err := s.ClaimEnrichmentJobs(ctx, 1000, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, job := range jobs {
+			if job.Person.GUID == guid {
+				return job, true
+			}
+		}
+
+		return models.EnrichmentJob{}, false
+	}
+
+	job, ok := claim()
+	if !ok {
+		t.Fatal("the job of a pending profile was not claimed")
+	}
+
+	if len(job.Person.PendingFields) != 3 {
+		t.Fatalf("expected the pending fields, got %v", job.Person.PendingFields)
+	}
+
+	if _, ok := claim(); ok {
+		t.Fatal("a leased job was claimed twice")
+	}
+
+	job.Attempts = 10
+	if err := s.RescheduleEnrichmentJob(ctx, job, time.Now(), "provider down", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := claim(); ok {
+		t.Fatal("a failed job was claimed")
+	}
+
+	profile, err := s.TakeProfile(ctx, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.EnrichmentStatus != models.EnrichmentFailed {
+		t.Fatalf("expected status %q, got %q", models.EnrichmentFailed, profile.EnrichmentStatus)
+	}
+}
+
+func TestNewProfile_DeferredQueuesJob(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := musicService.New(s, &stubEnricher{}, musicService.PolicyDeferred, models.ConfidencePolicy{}, musicService.GenderRulesOff, "", log)
+
+	id, err := service.NewProfile(ctx, models.NewPerson{Name: gofakeit.FirstName(), Surname: gofakeit.LastName()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guid := string(id)
+	t.Cleanup(func() {
+		_, _ = s.RemoveProfile(context.Background(), models.DeletePerson{GUID: guid})
+	})
+
+	profile, err := s.TakeProfile(ctx, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.EnrichmentStatus != models.EnrichmentPending || len(profile.PendingFields) != 3 {
+		t.Fatalf("expected a pending profile, got %q with %v", profile.EnrichmentStatus, profile.PendingFields)
+	}
+
+	jobs, err := s.ClaimEnrichmentJobs(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.ContainsFunc(jobs, func(job models.EnrichmentJob) bool { return job.Person.GUID == guid }) {
+		t.Fatal("no enrichment job was queued for the profile")
+	}
+}
+
+func TestTakeProfiles_RealProbabilities(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	surname := gofakeit.LetterN(12)
+	newStoredProfile(t, s, models.EnrichedPerson{
+		Surname:           surname,
+		Gender:            "male",
+		GenderProbability: 0.9,
+		Nationalize:       "RU",
+		Countries:         []models.Country{{CountryID: "RU", Probability: 0.9}},
+	})
+
+	cases := []filter.And{
+		{filter.Condition{Field: filter.FieldGenderProbability, Op: filter.OpEq, Values: []any{0.9}}},
+		{filter.Condition{Field: filter.FieldGenderProbability, Op: filter.OpGte, Values: []any{0.9}}},
+		{filter.Condition{Field: filter.FieldGenderProbability, Op: filter.OpIn, Values: []any{0.5, 0.9}}},
+		{filter.Condition{Field: filter.FieldCountryProbability, Op: filter.OpGte, Values: []any{0.9}}},
+	}
+
+	for _, cond := range cases {
+		page, err := s.TakeProfiles(ctx, models.GetPerson{Surname: surname, Page: 1, PageSize: 10, Filter: cond})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(page.Profiles) != 1 {
+			t.Errorf("%+v: expected the profile, got %d", cond[0], len(page.Profiles))
+		}
+	}
+
+	// The legacy minimums include a value equal to the minimum.
+	page, err := s.TakeProfiles(ctx, models.GetPerson{Surname: surname, Page: 1, PageSize: 10, MinGenderProbability: 0.9, MinCountryProbability: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Profiles) != 1 {
+		t.Errorf("min probabilities: expected the profile, got %d", len(page.Profiles))
+	}
+}
+
+func TestUpdateProfile_ManualNationalityDropsCountries(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	guid := newStoredProfile(t, s, models.EnrichedPerson{
+		Nationalize: "RU",
+		Countries:   []models.Country{{CountryID: "RU", Probability: 0.7}, {CountryID: "UA", Probability: 0.2}},
+	})
+
+	if _, err := s.UpdateProfile(ctx, models.UpdatedPerson{GUID: guid, Nationalize: "KZ"}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := s.TakeProfile(ctx, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Nationalize != "KZ" || len(profile.Countries) != 0 {
+		t.Fatalf("expected KZ without countries, got %q with %v", profile.Nationalize, profile.Countries)
+	}
+}
+
+func TestTakeProfiles_CursorTies(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	surname := gofakeit.LetterN(12)
+
+	const count = 5
+
+	stored := make(map[string]bool, count)
+	for range count {
+		guid := newStoredProfile(t, s, models.EnrichedPerson{
+			Surname:           surname,
+			Gender:            "female",
+			GenderProbability: 0.9,
+		})
+
+		stored[guid] = true
+	}
+
+	sort, err := filter.ParseSort("-gender_probability")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := models.GetPerson{Surname: surname, Page: 1, PageSize: 2, Sort: sort}
+
+	seen := make(map[string]bool, count)
+	for pages := 0; ; pages++ {
+		if pages > count {
+			t.Fatal("paging did not end")
+		}
+
+		page, err := s.TakeProfiles(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, p := range page.Profiles {
+			if seen[p.GUID] {
+				t.Fatalf("profile %s returned twice", p.GUID)
+			}
+
+			seen[p.GUID] = true
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		req.Cursor, err = filter.DecodeCursor(page.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for guid := range stored {
+		if !seen[guid] {
+			t.Errorf("profile %s was skipped between pages", guid)
+		}
+	}
+}
+
+func TestUpdateProfile_Errors(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	_, err := s.UpdateProfile(ctx, models.UpdatedPerson{GUID: gofakeit.UUID(), Age: 30})
+	if !errors.Is(err, storage.ErrProfileNotFound) {
+		t.Fatalf("expected profile not found, got %v", err)
+	}
+
+	guid := newStoredProfile(t, s, models.EnrichedPerson{})
+
+	// An invalid enum value is a database failure, not a missing profile.
+	_, err = s.UpdateProfile(ctx, models.UpdatedPerson{GUID: guid, Gender: "robot"})
+	if err == nil || errors.Is(err, storage.ErrProfileNotFound) {
+		t.Fatalf("expected a database error, got %v", err)
+	}
+}
+
+func TestUpdateProfile_Replace(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	guid := newStoredProfile(t, s, models.EnrichedPerson{
+		Patronymic:        "Ivanovich",
+		Age:               40,
+		Gender:            "male",
+		GenderProbability: 0.9,
+		Nationalize:       "RU",
+		Countries:         []models.Country{{CountryID: "RU", Probability: 0.9}},
+	})
+
+	_, err := s.UpdateProfile(ctx, models.UpdatedPerson{GUID: guid, Name: "Petr", Surname: "Petrov", Age: 33, Replace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := s.TakeProfile(ctx, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Name != "Petr" || profile.Surname != "Petrov" || profile.Age != 33 {
+		t.Fatalf("the sent fields were not saved: %+v", profile)
+	}
+
+	if profile.Patronymic != "" || profile.Gender != "" || profile.GenderProbability != 0 || profile.Nationalize != "" || len(profile.Countries) != 0 {
+		t.Fatalf("the fields left out were not cleared: %+v", profile)
+	}
+}
+
+func TestUpdateProfile_Provenance(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	inferred := models.FieldProvenance{Kind: models.ProvenanceInferred, Source: "api.agify.io"}
+
+	guid := newStoredProfile(t, s, models.EnrichedPerson{
+		Age:         40,
+		Gender:      "male",
+		Nationalize: "RU",
+		Provenance: models.Provenance{
+			models.FieldAge:         inferred,
+			models.FieldGender:      inferred,
+			models.FieldNationalize: inferred,
+		},
+	})
+
+	if _, err := s.UpdateProfile(ctx, models.UpdatedPerson{GUID: guid, Age: 33, Gender: "female"}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := s.TakeProfile(ctx, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for field, kind := range map[string]string{
+		models.FieldAge:         models.ProvenanceManual,
+		models.FieldGender:      models.ProvenanceManual,
+		models.FieldNationalize: models.ProvenanceInferred,
+	} {
+		if got := profile.Provenance[field].Kind; got != kind {
+			t.Fatalf("patch: expected %s to be %s, got %q", field, kind, got)
+		}
+	}
+
+	// Replacing without age and nationality clears them along with their
+	// provenance; the gender sent again stays manual.
+	_, err = s.UpdateProfile(ctx, models.UpdatedPerson{GUID: guid, Name: "Petr", Surname: "Petrov", Gender: "male", Replace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err = s.TakeProfile(ctx, guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := profile.Provenance[models.FieldGender].Kind; got != models.ProvenanceManual {
+		t.Fatalf("replace: expected gender to be manual, got %q", got)
+	}
+
+	for _, field := range []string{models.FieldAge, models.FieldNationalize} {
+		if p, ok := profile.Provenance[field]; ok {
+			t.Fatalf("replace: expected no provenance for the cleared %s, got %+v", field, p)
+		}
+	}
+}
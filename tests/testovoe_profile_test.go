@@ -11,7 +11,6 @@ import (
 )
 
 const (
-	host               = "localhost:8082"
 	pageSize           = 10
 	page               = 1
 	invalidName        = "Abcdefghijklmnopqrstwxzqwertyasdfgh"
@@ -22,6 +21,9 @@ const (
 	invalidNationalize = "Some Nationalize"
 )
 
+// problemJSON lets httpexpect decode application/problem+json error bodies.
+var problemJSON = httpexpect.ContentOpts{MediaType: "application/problem+json"}
+
 func TestMobileCreate_HappyPath(t *testing.T) {
 	u := url.URL{
 		Scheme: "http",
@@ -71,7 +73,7 @@ func TestMobileUpdate_HappyPath(t *testing.T) {
 			Patronymic:  gofakeit.MiddleName(),
 			Age:         gofakeit.Number(10, 80),
 			Gender:      gofakeit.Gender(),
-			Nationalize: gofakeit.Country(),
+			Nationalize: gofakeit.CountryAbr(),
 		}).
 		Expect().
 		Status(http.StatusOK)
@@ -89,7 +91,7 @@ func TestMobileGet_HappyPath(t *testing.T) {
 	surname := gofakeit.LastName()
 	patronymic := gofakeit.MiddleName()
 	gender := gofakeit.Gender()
-	nationalize := gofakeit.Country()
+	nationalize := gofakeit.CountryAbr()
 
 	guid := e.POST("/profile/new").
 		WithJSON(models.NewPerson{
@@ -125,6 +127,60 @@ func TestMobileGet_HappyPath(t *testing.T) {
 		Status(http.StatusOK)
 }
 
+func TestMobileGetByGUID_HappyPath(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	name := gofakeit.FirstName()
+	surname := gofakeit.LastName()
+
+	guid := e.POST("/profile/new").
+		WithJSON(models.NewPerson{
+			Name:    name,
+			Surname: surname,
+		}).Expect().
+		JSON().
+		Object().
+		Value("data").
+		String().Raw()
+
+	profile := e.GET("/api/v1/profiles/{guid}", guid).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("data").
+		Object()
+
+	profile.Value("guid").IsEqual(guid)
+	profile.Value("name").IsEqual(name)
+	profile.Value("surname").IsEqual(surname)
+	profile.ContainsKey("created_at")
+	profile.ContainsKey("updated_at")
+}
+
+func TestMobileGetByGUID_NotFound(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	problem := e.GET("/api/v1/profiles/{guid}", gofakeit.UUID()).
+		Expect().
+		Status(http.StatusNotFound).
+		JSON(problemJSON).Object()
+
+	problem.Value("type").IsEqual("/problems/profile-not-found")
+	problem.Value("status").IsEqual(http.StatusNotFound)
+	problem.Value("instance").String().NotEmpty()
+}
+
 func TestMobileDelete_HappyPath(t *testing.T) {
 	u := url.URL{
 		Scheme: "http",
@@ -165,25 +221,25 @@ func TestCreate_FailCases(t *testing.T) {
 			title:     "Create profile with empty name",
 			name:      "",
 			surname:   gofakeit.LastName(),
-			respError: "field Name is a required field",
+			respError: "name is a required field",
 		},
 		{
 			title:     "Create profile with empty surname",
 			name:      gofakeit.FirstName(),
 			surname:   "",
-			respError: "field Surname is a required field",
+			respError: "surname is a required field",
 		},
 		{
 			title:     "Create profile with too large name",
 			name:      invalidName,
 			surname:   gofakeit.LastName(),
-			respError: "field Name must have less than 20 characters",
+			respError: "name must be a maximum of 20 characters in length",
 		},
 		{
 			title:     "Create profile with too large surname",
 			name:      gofakeit.FirstName(),
 			surname:   invalidSurname,
-			respError: "field Surname must have less than 30 characters",
+			respError: "surname must be a maximum of 30 characters in length",
 		},
 	}
 
@@ -201,12 +257,14 @@ func TestCreate_FailCases(t *testing.T) {
 				WithJSON(models.NewPerson{
 					Name:    tt.name,
 					Surname: tt.surname,
-				}).Expect().JSON().Object()
+				}).Expect().
+				Status(http.StatusUnprocessableEntity).
+				JSON(problemJSON).Object()
 
 			if tt.respError != "" {
 				resp.NotContainsKey("data")
 
-				resp.Value("error").String().IsEqual(tt.respError)
+				resp.Value("detail").String().IsEqual(tt.respError)
 
 				return
 			}
@@ -232,8 +290,8 @@ func TestUpdate_FailCases(t *testing.T) {
 			patronymic:  gofakeit.MiddleName(),
 			age:         gofakeit.Number(10, 80),
 			gender:      "RU",
-			nationalize: gofakeit.Country(),
-			respError:   "field GUID is a required field",
+			nationalize: gofakeit.CountryAbr(),
+			respError:   "guid is a required field",
 		},
 		{
 			title:       "Update with invalid name",
@@ -243,7 +301,7 @@ func TestUpdate_FailCases(t *testing.T) {
 			age:         gofakeit.Number(10, 80),
 			gender:      gofakeit.Gender(),
 			nationalize: "RU",
-			respError:   "field Name must have less than 20 characters",
+			respError:   "new_name must be a maximum of 20 characters in length",
 		},
 		{
 			title:       "Update with invalid surname",
@@ -253,7 +311,7 @@ func TestUpdate_FailCases(t *testing.T) {
 			age:         gofakeit.Number(10, 80),
 			gender:      gofakeit.Gender(),
 			nationalize: "RU",
-			respError:   "field Surname must have less than 30 characters",
+			respError:   "new_surname must be a maximum of 30 characters in length",
 		},
 		{
 			title:       "Update with invalid patronymic",
@@ -263,7 +321,7 @@ func TestUpdate_FailCases(t *testing.T) {
 			age:         gofakeit.Number(10, 80),
 			gender:      gofakeit.Gender(),
 			nationalize: "RU",
-			respError:   "field Patronymic must have less than 25 characters",
+			respError:   "patronymic must be a maximum of 25 characters in length",
 		},
 		{
 			title:       "Update with invalid age",
@@ -273,7 +331,7 @@ func TestUpdate_FailCases(t *testing.T) {
 			age:         invalidAge,
 			gender:      gofakeit.Gender(),
 			nationalize: "RU",
-			respError:   "field Age must have less than 130",
+			respError:   "age must be 130 or less",
 		},
 		{
 			title:       "Update with invalid gender",
@@ -283,7 +341,7 @@ func TestUpdate_FailCases(t *testing.T) {
 			age:         gofakeit.Number(10, 80),
 			gender:      invalidGender,
 			nationalize: "RU",
-			respError:   "field Gender must have less than 6 characters",
+			respError:   "gender must be one of [male female other]",
 		},
 		{
 			title:       "Update with invalid nationalize",
@@ -293,7 +351,7 @@ func TestUpdate_FailCases(t *testing.T) {
 			age:         gofakeit.Number(10, 80),
 			gender:      gofakeit.Gender(),
 			nationalize: invalidNationalize,
-			respError:   "field Nationalize must have less than 3 characters",
+			respError:   "nationalize must be an ISO 3166-1 alpha-2 country code",
 		},
 	}
 
@@ -320,12 +378,12 @@ func TestUpdate_FailCases(t *testing.T) {
 				resp := e.PATCH("/profile/update").
 					WithJSON(models.UpdatedPerson{
 						GUID: "",
-					}).Expect().JSON().Object()
+					}).Expect().JSON(problemJSON).Object()
 
 				if tt.respError != "" {
 					resp.NotContainsKey("data")
 
-					resp.Value("error").String().IsEqual(tt.respError)
+					resp.Value("detail").String().IsEqual(tt.respError)
 
 					return
 				}
@@ -340,12 +398,12 @@ func TestUpdate_FailCases(t *testing.T) {
 					Age: tt.age,
 					Gender: tt.gender,
 					Nationalize: tt.nationalize,
-				}).Expect().JSON().Object()
+				}).Expect().JSON(problemJSON).Object()
 
 			if tt.respError != "" {
 				resp.NotContainsKey("data")
 
-				resp.Value("error").String().IsEqual(tt.respError)
+				resp.Value("detail").String().IsEqual(tt.respError)
 
 				return
 			}
@@ -363,12 +421,12 @@ func TestGet_FailCases(t *testing.T) {
 		{
 			title:       "Get profiles without number of page",
 			pageSize:     5,
-			respError: "field Page is a required field",
+			respError: "page is a required field",
 		},
 		{
 			title:       "Get profiles without size of page",
 			page:     5,
-			respError: "field PageSize is a required field",
+			respError: "page_size is a required field",
 		},
 	}
 
@@ -385,12 +443,12 @@ func TestGet_FailCases(t *testing.T) {
 				WithJSON(models.GetPerson{
 					Page:    tt.page,
 					PageSize: tt.pageSize,
-				}).Expect().JSON().Object()
+				}).Expect().JSON(problemJSON).Object()
 
 			if tt.respError != "" {
 				resp.NotContainsKey("data")
 
-				resp.Value("error").String().IsEqual(tt.respError)
+				resp.Value("detail").String().IsEqual(tt.respError)
 
 				return
 			}
@@ -407,7 +465,7 @@ func TestDelete_FailCases(t *testing.T) {
 		{
 			title:     "Delete profile with empty GUID",
 			guid:      "",
-			respError: "field GUID is a required field",
+			respError: "guid is a required field",
 		},
 		{
 			title:     "Delete non-existent profile",
@@ -428,12 +486,12 @@ func TestDelete_FailCases(t *testing.T) {
 			resp := e.DELETE("/profile/delete").
 				WithJSON(models.DeletePerson{
 					GUID: tt.guid,
-				}).Expect().JSON().Object()
+				}).Expect().JSON(problemJSON).Object()
 
 			if tt.respError != "" {
 				resp.NotContainsKey("data")
 
-				resp.Value("error").String().IsEqual(tt.respError)
+				resp.Value("detail").String().IsEqual(tt.respError)
 
 				return
 			}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stepan41k/Effective-Mobile/internal/lib/translit"
+)
+
+func TestTransliterate(t *testing.T) {
+	cases := []struct {
+		title  string
+		input  string
+		scheme translit.Scheme
+		want   string
+	}{
+		{title: "ICAO yo", input: "Пётр", scheme: translit.SchemeICAO, want: "Petr"},
+		{title: "GOST yo", input: "Пётр", scheme: translit.SchemeGOST, want: "Pyotr"},
+		{title: "ICAO short i", input: "Андрей", scheme: translit.SchemeICAO, want: "Andrei"},
+		{title: "GOST short i", input: "Андрей", scheme: translit.SchemeGOST, want: "Andrej"},
+		{title: "ICAO shch", input: "Щукин", scheme: translit.SchemeICAO, want: "Shchukin"},
+		{title: "GOST shch", input: "Щукин", scheme: translit.SchemeGOST, want: "Shhukin"},
+		{title: "ICAO hard sign", input: "Подъячев", scheme: translit.SchemeICAO, want: "Podieiachev"},
+		{title: "GOST hard sign", input: "Подъячев", scheme: translit.SchemeGOST, want: "Podyachev"},
+		{title: "ICAO soft sign", input: "Игорь", scheme: translit.SchemeICAO, want: "Igor"},
+		{title: "GOST soft sign", input: "Ильич", scheme: translit.SchemeGOST, want: "Ilich"},
+		{title: "ICAO ts", input: "Цветкова", scheme: translit.SchemeICAO, want: "Tsvetkova"},
+		{title: "GOST c before e", input: "Цецилия", scheme: translit.SchemeGOST, want: "Ceciliya"},
+		{title: "GOST cz elsewhere", input: "Цветкова", scheme: translit.SchemeGOST, want: "Czvetkova"},
+		{title: "Upper-case word", input: "ЖУКОВ", scheme: translit.SchemeICAO, want: "ZHUKOV"},
+		{title: "Upper-case last letter", input: "ЮЩ", scheme: translit.SchemeICAO, want: "IUSHCH"},
+		{title: "Title case digraph", input: "Юлия", scheme: translit.SchemeICAO, want: "Iuliia"},
+		{title: "Mixed case", input: "иВАН", scheme: translit.SchemeICAO, want: "iVAN"},
+		{title: "Hyphenated", input: "Щедрин-Жуков", scheme: translit.SchemeICAO, want: "Shchedrin-Zhukov"},
+		{title: "Ukrainian letters", input: "Їжак Євген", scheme: translit.SchemeGOST, want: "Yizhak Yevgen"},
+		{title: "Latin passes through ICAO", input: "O'Neil-Smith", scheme: translit.SchemeICAO, want: "O'Neil-Smith"},
+		{title: "Latin passes through GOST", input: "Jean Luc", scheme: translit.SchemeGOST, want: "Jean Luc"},
+		{title: "Mixed scripts", input: "Ivan Щ", scheme: translit.SchemeICAO, want: "Ivan Shch"},
+		{title: "Scheme none", input: "Пётр", scheme: translit.SchemeNone, want: "Пётр"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := translit.Transliterate(tt.input, tt.scheme); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	for input, want := range map[string]translit.Scheme{"": translit.SchemeNone, "ICAO": translit.SchemeICAO, "gost": translit.SchemeGOST} {
+		got, err := translit.ParseScheme(input)
+		if err != nil || got != want {
+			t.Fatalf("%q: expected %q, got %q, %v", input, want, got, err)
+		}
+	}
+
+	if _, err := translit.ParseScheme("bgn"); err == nil {
+		t.Fatal("expected an error for an unknown scheme")
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/filter"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	resp "github.com/stepan41k/Effective-Mobile/internal/lib/api/response"
+	"github.com/stepan41k/Effective-Mobile/internal/lib/api/validate"
+)
+
+func TestValidationProblem_FieldErrors(t *testing.T) {
+	cases := []struct {
+		title          string
+		acceptLanguage string
+		message        string
+	}{
+		{
+			title:   "Without Accept-Language",
+			message: "new_name must be a maximum of 20 characters in length",
+		},
+		{
+			title:          "Russian preferred",
+			acceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8",
+			message:        "new_name должен содержать максимум 20 символов",
+		},
+		{
+			title:          "Unsupported language falls back to English",
+			acceptLanguage: "de, ru;q=0.1, en;q=0.5",
+			message:        "new_name must be a maximum of 20 characters in length",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := validate.Struct(models.UpdatedPerson{GUID: "guid", Name: invalidName})
+
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+
+			problem := resp.ValidationProblem(errs, validate.Translator(tt.acceptLanguage))
+
+			want := resp.FieldError{
+				Field:       "new_name",
+				JSONPointer: "/new_name",
+				Rule:        "max",
+				Param:       "20",
+				Message:     tt.message,
+			}
+
+			if len(problem.Errors) != 1 || problem.Errors[0] != want {
+				t.Fatalf("expected %+v, got %+v", want, problem.Errors)
+			}
+		})
+	}
+}
+
+func TestValidate_DomainRules(t *testing.T) {
+	cases := []struct {
+		title  string
+		person models.UpdatedPerson
+		rule   string
+	}{
+		{title: "Gender from the enum", person: models.UpdatedPerson{Gender: "other"}},
+		{title: "Gender outside the enum", person: models.UpdatedPerson{Gender: "Panzer"}, rule: "gender"},
+		{title: "Country code", person: models.UpdatedPerson{Nationalize: "RU"}},
+		{title: "Lowercase country code", person: models.UpdatedPerson{Nationalize: "kz"}},
+		{title: "Unassigned country code", person: models.UpdatedPerson{Nationalize: "XX"}, rule: "country"},
+		{title: "Country name", person: models.UpdatedPerson{Nationalize: "Russia"}, rule: "country"},
+		{title: "Hyphenated name", person: models.UpdatedPerson{Name: "Jean-Luc"}},
+		{title: "Name with apostrophe", person: models.UpdatedPerson{Surname: "O'Neil"}},
+		{title: "Cyrillic name", person: models.UpdatedPerson{Name: "Пётр"}},
+		{title: "Name with digits", person: models.UpdatedPerson{Name: "R2D2"}, rule: "personname"},
+		{title: "Name starting with hyphen", person: models.UpdatedPerson{Name: "-Ann"}, rule: "personname"},
+		{title: "Name with space", person: models.UpdatedPerson{Patronymic: "Ann Marie"}, rule: "personname"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			tt.person.GUID = "guid"
+
+			err := validate.Struct(tt.person)
+
+			if tt.rule == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Tag() != tt.rule {
+				t.Fatalf("expected %s to fail, got %v", tt.rule, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Paging(t *testing.T) {
+	cursor := &filter.Cursor{GUID: "guid"}
+
+	cases := []struct {
+		title  string
+		person models.GetPerson
+		rule   string
+	}{
+		{title: "First page", person: models.GetPerson{Page: 1, PageSize: 10}},
+		{title: "Largest page size", person: models.GetPerson{Page: 1, PageSize: 100}},
+		{title: "Cursor without page", person: models.GetPerson{PageSize: 10, Cursor: cursor}},
+		{title: "Missing page", person: models.GetPerson{PageSize: 10}, rule: "required_without"},
+		{title: "Negative page", person: models.GetPerson{Page: -1, PageSize: 10}, rule: "gte"},
+		{title: "Negative page with cursor", person: models.GetPerson{Page: -1, PageSize: 10, Cursor: cursor}, rule: "gte"},
+		{title: "Negative page size", person: models.GetPerson{Page: 1, PageSize: -1}, rule: "gte"},
+		{title: "Page size too large", person: models.GetPerson{Page: 1, PageSize: 101}, rule: "lte"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.title, func(t *testing.T) {
+			err := validate.Struct(tt.person)
+
+			if tt.rule == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Tag() != tt.rule {
+				t.Fatalf("expected %s to fail, got %v", tt.rule, err)
+			}
+		})
+	}
+}
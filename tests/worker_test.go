@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Effective-Mobile/internal/app/worker"
+	"github.com/stepan41k/Effective-Mobile/internal/config"
+	"github.com/stepan41k/Effective-Mobile/internal/domain/models"
+	"github.com/stepan41k/Effective-Mobile/internal/enrichment"
+)
+
+// fakeJobs hands out its jobs once and records what the worker did with
+// them.
+type fakeJobs struct {
+	mu          sync.Mutex
+	queue       []models.EnrichmentJob
+	deleted     []int64
+	rescheduled []models.EnrichmentJob
+	runAt       []time.Time
+	failed      []bool
+}
+
+func (f *fakeJobs) ClaimEnrichmentJobs(ctx context.Context, limit int, lease time.Duration) ([]models.EnrichmentJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := min(limit, len(f.queue))
+	jobs := f.queue[:n]
+	f.queue = f.queue[n:]
+
+	return jobs, nil
+}
+
+func (f *fakeJobs) DeleteEnrichmentJob(ctx context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deleted = append(f.deleted, id)
+
+	return nil
+}
+
+func (f *fakeJobs) RescheduleEnrichmentJob(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr string, failed bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rescheduled = append(f.rescheduled, job)
+	f.runAt = append(f.runAt, runAt)
+	f.failed = append(f.failed, failed)
+
+	return nil
+}
+
+type fakeProfiles func(ctx context.Context, person models.EnrichedPerson) error
+
+func (f fakeProfiles) EnrichProfile(ctx context.Context, person models.EnrichedPerson) ([]string, error) {
+	return nil, f(ctx, person)
+}
+
+func workerConfig() config.Worker {
+	return config.Worker{
+		Workers:        1,
+		BatchSize:      10,
+		PollInterval:   time.Millisecond,
+		Lease:          time.Minute,
+		JobTimeout:     time.Second,
+		MaxAttempts:    3,
+		RetryBaseDelay: time.Second,
+		RetryMaxDelay:  time.Minute,
+	}
+}
+
+func TestWorker_ProcessesJobs(t *testing.T) {
+	jobs := &fakeJobs{queue: []models.EnrichmentJob{
+		{ID: 1, Person: models.EnrichedPerson{GUID: "ok"}},
+		{ID: 2, Attempts: 0, Person: models.EnrichedPerson{GUID: "retry"}},
+		{ID: 3, Attempts: 2, Person: models.EnrichedPerson{GUID: "exhausted"}},
+	}}
+
+	profiles := fakeProfiles(func(ctx context.Context, person models.EnrichedPerson) error {
+		if person.GUID == "ok" {
+			return nil
+		}
+
+		return errors.New("provider down")
+	})
+
+	app := worker.New(slog.New(slog.NewTextHandler(io.Discard, nil)), workerConfig(), jobs, profiles)
+	app.Run()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		jobs.mu.Lock()
+		done := len(jobs.deleted)+len(jobs.rescheduled) == 3
+		jobs.mu.Unlock()
+
+		if done {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("jobs were not processed in time")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	app.Stop(ctx)
+
+	if len(jobs.deleted) != 1 || jobs.deleted[0] != 1 {
+		t.Fatalf("expected job 1 to be deleted, got %v", jobs.deleted)
+	}
+
+	for i, job := range jobs.rescheduled {
+		wantFailed := job.ID == 3
+		if jobs.failed[i] != wantFailed {
+			t.Fatalf("job %d: expected failed=%v after %d attempts", job.ID, wantFailed, job.Attempts)
+		}
+	}
+}
+
+func TestWorker_PostponesRateLimitedJobs(t *testing.T) {
+	jobs := &fakeJobs{queue: []models.EnrichmentJob{{ID: 1, Attempts: 2, Person: models.EnrichedPerson{GUID: "limited"}}}}
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	profiles := fakeProfiles(func(ctx context.Context, person models.EnrichedPerson) error {
+		return &enrichment.Error{Providers: []*enrichment.ProviderError{
+			{Provider: enrichment.ProviderAge, Err: &enrichment.RateLimitError{Until: until}},
+		}}
+	})
+
+	app := worker.New(slog.New(slog.NewTextHandler(io.Discard, nil)), workerConfig(), jobs, profiles)
+	app.Run()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		jobs.mu.Lock()
+		done := len(jobs.rescheduled) == 1
+		jobs.mu.Unlock()
+
+		if done {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("job was not processed in time")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	app.Stop(ctx)
+
+	// The job is one attempt short of failing, so counting the rate limit
+	// as an attempt would fail it.
+	if job := jobs.rescheduled[0]; job.Attempts != 2 || jobs.failed[0] {
+		t.Fatalf("rate limited job counted as an attempt: attempts %d, failed %v", job.Attempts, jobs.failed[0])
+	}
+
+	if !jobs.runAt[0].Equal(until) {
+		t.Fatalf("expected the job to run at %v, got %v", until, jobs.runAt[0])
+	}
+}
+
+func TestWorker_StopCancelsInFlightJobs(t *testing.T) {
+	jobs := &fakeJobs{queue: []models.EnrichmentJob{{ID: 1, Person: models.EnrichedPerson{GUID: "slow"}}}}
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	profiles := fakeProfiles(func(ctx context.Context, person models.EnrichedPerson) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+
+		return ctx.Err()
+	})
+
+	cfg := workerConfig()
+	cfg.JobTimeout = time.Hour
+
+	app := worker.New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, jobs, profiles)
+	app.Run()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		app.Stop(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the shutdown timeout")
+	}
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("in-flight job was not cancelled")
+	}
+
+	// An interrupted job is left for its lease to expire, not counted as
+	// a failed attempt.
+	if len(jobs.rescheduled) != 0 || len(jobs.deleted) != 0 {
+		t.Fatalf("interrupted job was settled: deleted %v, rescheduled %v", jobs.deleted, jobs.rescheduled)
+	}
+}